@@ -0,0 +1,304 @@
+// Package cachertest cacher.Cacher 的一致性测试，redis 与 memory 插件共用，保证两者行为一致
+package cachertest
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/livexy/plugin/cacher"
+)
+
+// Options 被测实现的构造方式
+type Options struct {
+	// 创建空的缓存实例，前缀须为 Prefix，同时返回推进时间使过期生效的方法
+	// Redis 测试服务可快进时钟，进程内实现直接等待
+	New func(t *testing.T) (c cacher.Cacher, advance func(d time.Duration))
+	// 缓存前缀，用于校验返回完整键的方法
+	Prefix string
+}
+
+// 单个测试的被测实例
+type env struct {
+	cacher.Cacher
+	advance func(d time.Duration)
+	prefix  string
+}
+
+// Run 执行全部一致性测试
+func Run(t *testing.T, opt Options) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, c env)
+	}{
+		{"String", testString},
+		{"Counter", testCounter},
+		{"Keys", testKeys},
+		{"Expire", testExpire},
+		{"Pattern", testPattern},
+		{"Lock", testLock},
+		{"Hash", testHash},
+		{"Bit", testBit},
+		{"List", testList},
+		{"FlushDB", testFlushDB},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, advance := opt.New(t)
+			tt.fn(t, env{Cacher: c, advance: advance, prefix: opt.Prefix})
+		})
+	}
+}
+
+func testString(t *testing.T, c env) {
+	if !c.Set("a", "1", 0) {
+		t.Fatal("Set failed")
+	}
+	if v := c.Get("a"); v != "1" {
+		t.Fatalf("Get = %q", v)
+	}
+	if v := string(c.GetBytes("a")); v != "1" {
+		t.Fatalf("GetBytes = %q", v)
+	}
+	if c.GetInt("a") != 1 || c.GetInt64("a") != 1 {
+		t.Fatal("GetInt/GetInt64 mismatch")
+	}
+	if v := c.Get("missing"); v != "" {
+		t.Fatalf("Get missing = %q", v)
+	}
+	if c.GetBytes("missing") != nil {
+		t.Fatal("GetBytes missing not nil")
+	}
+	if c.SetNX("a", "2", 0) {
+		t.Fatal("SetNX on existing key")
+	}
+	if !c.SetNX("b", "2", 0) {
+		t.Fatal("SetNX on new key")
+	}
+	if !c.SetXX("a", "3", 0) || c.Get("a") != "3" {
+		t.Fatal("SetXX on existing key")
+	}
+	if c.SetXX("missing", "x", 0) {
+		t.Fatal("SetXX on missing key")
+	}
+	if old := c.GetSet("a", "4"); old != "3" || c.Get("a") != "4" {
+		t.Fatalf("GetSet old = %q", old)
+	}
+	if old := c.GetSet("fresh", "5"); old != "" || c.Get("fresh") != "5" {
+		t.Fatalf("GetSet missing old = %q", old)
+	}
+	vals := c.MGet("a", "missing", "b")
+	if len(vals) != 3 || vals[0] != "4" || vals[1] != nil || vals[2] != "2" {
+		t.Fatalf("MGet = %v", vals)
+	}
+}
+
+func testCounter(t *testing.T, c env) {
+	if c.Incr("n") != 1 || c.IncrBy("n", 5) != 6 {
+		t.Fatal("Incr/IncrBy mismatch")
+	}
+	if c.Decr("n") != 5 || c.DecrBy("n", 10) != -5 {
+		t.Fatal("Decr/DecrBy mismatch")
+	}
+	if c.GetInt64("n") != -5 {
+		t.Fatalf("GetInt64 = %d", c.GetInt64("n"))
+	}
+	c.Set("s", "abc", 0)
+	if c.Incr("s") != 0 {
+		t.Fatal("Incr on non-integer should fail")
+	}
+	c.Set("max", math.MaxInt64, 0)
+	if c.Incr("max") != 0 || c.GetInt64("max") != math.MaxInt64 {
+		t.Fatal("Incr overflow should fail")
+	}
+}
+
+func testKeys(t *testing.T, c env) {
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Set("c", 3, 0)
+	c.Set("d", 4, 0)
+	if n := c.Exists("a", "b", "missing"); n != 2 {
+		t.Fatalf("Exists = %d", n)
+	}
+	if !c.Delete("a") || c.Exists("a") != 0 {
+		t.Fatal("Delete")
+	}
+	if !c.Unlink("b") || c.Exists("b") != 0 {
+		t.Fatal("Unlink")
+	}
+	if !c.DeleteKeys(c.prefix+":c") || c.Exists("c") != 0 {
+		t.Fatal("DeleteKeys")
+	}
+	if !c.UnlinkKeys(c.prefix+":d") || c.Exists("d") != 0 {
+		t.Fatal("UnlinkKeys")
+	}
+}
+
+func testExpire(t *testing.T, c env) {
+	c.Set("ttl", "v", 100*time.Millisecond)
+	c.Set("exp", "v", 0)
+	c.Set("pexp", "v", 0)
+	c.Set("at", "v", 0)
+	c.Set("keep", "v", 0)
+	if !c.Expire("exp", time.Second) || !c.PExpire("pexp", 100*time.Millisecond) {
+		t.Fatal("Expire/PExpire on existing key")
+	}
+	if c.Expire("missing", time.Second) || c.PExpire("missing", time.Second) {
+		t.Fatal("Expire on missing key")
+	}
+	// EXPIREAT 精度为秒，留足余量避免提前过期
+	if !c.ExpireAt("at", time.Now().Add(2*time.Second)) || !c.PExpireAt("keep", time.Now().Add(time.Hour)) {
+		t.Fatal("ExpireAt/PExpireAt on existing key")
+	}
+	c.advance(150 * time.Millisecond)
+	if c.Exists("ttl") != 0 || c.Exists("pexp") != 0 {
+		t.Fatal("keys not expired")
+	}
+	if c.Get("exp") != "v" || c.Get("at") != "v" {
+		t.Fatal("keys expired too early")
+	}
+	c.advance(2 * time.Second)
+	if c.Exists("exp", "at") != 0 {
+		t.Fatal("keys not expired")
+	}
+	if c.Get("keep") != "v" {
+		t.Fatal("PExpireAt key expired too early")
+	}
+}
+
+func testPattern(t *testing.T, c env) {
+	c.Set("user:1", 1, 0)
+	c.Set("user:2", 2, 0)
+	c.HSet("user:3", "f", 1)
+	c.Set("order:1", 1, 0)
+	want := []string{c.prefix + ":user:1", c.prefix + ":user:2", c.prefix + ":user:3"}
+	for _, keys := range [][]string{c.GetPatternKeys("user:"), c.GetPatternScan("user:")} {
+		slices.Sort(keys)
+		if !slices.Equal(keys, want) {
+			t.Fatalf("pattern keys = %v", keys)
+		}
+	}
+	if keys := c.GetPatternScan("none:"); len(keys) != 0 {
+		t.Fatalf("pattern keys = %v", keys)
+	}
+	// 与 SCAN MATCH 相同按通配匹配
+	for pattern, want := range map[string][]string{
+		"user:[12]": {c.prefix + ":user:1", c.prefix + ":user:2"},
+		"user:[^1]": {c.prefix + ":user:2", c.prefix + ":user:3"},
+		"*:1":       {c.prefix + ":order:1", c.prefix + ":user:1"},
+		"?ser:3":    {c.prefix + ":user:3"},
+		`user\:`:    {c.prefix + ":user:1", c.prefix + ":user:2", c.prefix + ":user:3"},
+	} {
+		keys := c.GetPatternScan(pattern)
+		slices.Sort(keys)
+		if !slices.Equal(keys, want) {
+			t.Fatalf("pattern %q keys = %v", pattern, keys)
+		}
+	}
+}
+
+func testLock(t *testing.T, c env) {
+	if c.LockStart("job") {
+		t.Fatal("first LockStart should succeed")
+	}
+	if !c.LockStart("job") {
+		t.Fatal("second LockStart should report locked")
+	}
+	c.LockEnd("job")
+	if c.LockStart("job", 1) {
+		t.Fatal("LockStart after LockEnd should succeed")
+	}
+}
+
+func testHash(t *testing.T, c env) {
+	if n := c.HSet("h", "a", "1", "b", "2"); n != 2 {
+		t.Fatalf("HSet = %d", n)
+	}
+	if n := c.HSet("h", map[string]any{"b": "3", "c": "4"}); n != 1 {
+		t.Fatalf("HSet map = %d", n)
+	}
+	if !c.HExists("h", "a") || c.HExists("h", "x") {
+		t.Fatal("HExists")
+	}
+	if c.HGet("h", "b") != "3" || string(c.HGetBytes("h", "c")) != "4" || c.HGetInt64("h", "a") != 1 {
+		t.Fatal("HGet mismatch")
+	}
+	if c.HGet("h", "x") != "" || c.HGetBytes("h", "x") != nil {
+		t.Fatal("HGet missing field")
+	}
+	all := c.HGetAll("h")
+	if len(all) != 3 || all["a"] != "1" || all["b"] != "3" || all["c"] != "4" {
+		t.Fatalf("HGetAll = %v", all)
+	}
+	keys := c.HKeys("h")
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"a", "b", "c"}) {
+		t.Fatalf("HKeys = %v", keys)
+	}
+	if c.HIncrBy("h", "a", 5) != 6 || c.HIncrBy("h", "n", 2) != 2 {
+		t.Fatal("HIncrBy mismatch")
+	}
+	if c.HLen("h") != 4 {
+		t.Fatalf("HLen = %d", c.HLen("h"))
+	}
+	if n := c.HDel("h", "a", "x"); n != 1 {
+		t.Fatalf("HDel = %d", n)
+	}
+	if len(c.HGetAll("missing")) != 0 || c.HLen("missing") != 0 {
+		t.Fatal("missing hash not empty")
+	}
+	c.Set("s", "v", 0)
+	if c.HSet("s", "f", "v") != 0 {
+		t.Fatal("HSet on string key should fail")
+	}
+}
+
+func testBit(t *testing.T, c env) {
+	if c.SetBit("b", 7, 1) != 0 || c.SetBit("b", 7, 1) != 1 {
+		t.Fatal("SetBit old value mismatch")
+	}
+	c.SetBit("b", 9, 1)
+	if c.GetBit("b", 7) != 1 || c.GetBit("b", 8) != 0 || c.GetBit("b", 100) != 0 {
+		t.Fatal("GetBit mismatch")
+	}
+	if c.BitCount("b", 0, -1) != 2 || c.BitCount("b", 1, 1) != 1 || c.BitCount("missing", 0, -1) != 0 {
+		t.Fatal("BitCount mismatch")
+	}
+}
+
+func testList(t *testing.T, c env) {
+	if c.RPush("l", "b", "c") != 2 || c.LPush("l", "a") != 3 {
+		t.Fatal("push length mismatch")
+	}
+	if c.LLen("l") != 3 {
+		t.Fatalf("LLen = %d", c.LLen("l"))
+	}
+	if v := c.LRange("l", 0, -1); !slices.Equal(v, []string{"a", "b", "c"}) {
+		t.Fatalf("LRange = %v", v)
+	}
+	if v := c.LRange("l", -2, 10); !slices.Equal(v, []string{"b", "c"}) {
+		t.Fatalf("LRange = %v", v)
+	}
+	if string(c.LPop("l")) != "a" || string(c.RPop("l")) != "c" || string(c.RPop("l")) != "b" {
+		t.Fatal("pop mismatch")
+	}
+	if c.LPop("l") != nil || c.RPop("l") != nil || c.LLen("l") != 0 {
+		t.Fatal("empty list")
+	}
+	if c.Exists("l") != 0 {
+		t.Fatal("empty list should be removed")
+	}
+	if c.LPush("l") != 0 || c.RPush("l") != 0 || c.Exists("l") != 0 {
+		t.Fatal("push without values should fail")
+	}
+}
+
+func testFlushDB(t *testing.T, c env) {
+	c.Set("a", 1, 0)
+	c.HSet("h", "f", 1)
+	if !c.FlushDB() || c.Exists("a", "h") != 0 {
+		t.Fatal("FlushDB")
+	}
+}
@@ -0,0 +1,34 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/livexy/plugins/dbbasetest"
+)
+
+func TestDryRun(t *testing.T) {
+	dbbasetest.Run(t, dbbasetest.Open(t, dialect, dialect.Open("dm://test:test@127.0.0.1:5236")), dbbasetest.Suite{
+		Upsert: dbbasetest.Snapshots{
+			"model":         `MERGE INTO stock USING (SELECT 'a' AS name, 2 AS qty, 1 AS id FROM DUAL) excluded ON (stock.id = excluded.id) WHEN MATCHED THEN UPDATE SET name = excluded.name, qty = excluded.qty WHEN NOT MATCHED THEN INSERT (name, qty, id) VALUES (excluded.name, excluded.qty, excluded.id)`,
+			"autoIncrement": `error: upsert 需要指定冲突列：插入列中没有冲突列 id，自增主键为零时请指定其他冲突列`,
+			"updates":       `MERGE INTO stock USING (SELECT 1 AS id, 'a' AS name, 2 AS qty FROM DUAL UNION ALL SELECT 2 AS id, 'b' AS name, 3 AS qty FROM DUAL) excluded ON (stock.id = excluded.id) WHEN MATCHED THEN UPDATE SET name = excluded.name WHEN NOT MATCHED THEN INSERT (id, name, qty) VALUES (excluded.id, excluded.name, excluded.qty)`,
+			"increments":    `MERGE INTO stock USING (SELECT 1 AS id, 'a' AS name, 2 AS qty FROM DUAL UNION ALL SELECT 2 AS id, 'b' AS name, 3 AS qty FROM DUAL) excluded ON (stock.id = excluded.id) WHEN MATCHED THEN UPDATE SET qty = stock.qty + 1 WHEN NOT MATCHED THEN INSERT (id, name, qty) VALUES (excluded.id, excluded.name, excluded.qty)`,
+			"doNothing":     `MERGE INTO stock USING (SELECT 1 AS id, 'a' AS name, 2 AS qty FROM DUAL UNION ALL SELECT 2 AS id, 'b' AS name, 3 AS qty FROM DUAL) excluded ON (stock.id = excluded.id) WHEN NOT MATCHED THEN INSERT (id, name, qty) VALUES (excluded.id, excluded.name, excluded.qty)`,
+		},
+		Helper: dbbasetest.Snapshots{
+			"date":      `SELECT TRUNC(created_at, 'DD'), CAST(created_at AS TIMESTAMP(0)), TO_CHAR(created_at, 'YYYY-MM-DD HH24:MI:SS'), DATEADD(MONTH, -1, created_at), DATEADD(HOUR, 2, created_at), DATEDIFF(DAY, created_at, updated_at), DATEDIFF(MONTH, created_at, updated_at) FROM event`,
+			"string":    `SELECT (first_name || ' ' || last_name), SUBSTR(name, 2, 3), SUBSTR(name, 2) FROM person WHERE LOWER(name) LIKE LOWER('%it''s%')`,
+			"json":      `SELECT JSON_VALUE(data, '$.user.name') FROM event WHERE active = 1`,
+			"aggregate": `SELECT dept, LISTAGG(name, '; ') WITHIN GROUP (ORDER BY name) FROM person GROUP BY dept`,
+			"paging": `SELECT id FROM event ORDER BY RAND() OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY;
+SELECT id FROM event ORDER BY id OFFSET 5 ROWS`,
+		},
+		CRUD: dbbasetest.Snapshots{
+			"create": `INSERT INTO stock (name,qty) VALUES ('a',1)`,
+			"find":   `SELECT * FROM stock  WHERE qty > 1 ORDER BY id  OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY`,
+			"update": `UPDATE stock SET name='b',qty=qty + 1  WHERE id = 1`,
+			"delete": `DELETE FROM stock  WHERE stock.id = 1`,
+			"cond":   `SELECT id, nvl(name, '') AS name, CASE WHEN qty > 10 THEN 'many' ELSE 'few' END AS level FROM stock`,
+		},
+	})
+}
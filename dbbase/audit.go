@@ -0,0 +1,528 @@
+package dbbase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// 审计动作
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+	ActionUpsert = "upsert" // Upsert 的冲突列不在插入列中，无法区分插入与更新
+)
+
+// Change 一行数据的变更
+type Change struct {
+	Table    string         `json:"table"`
+	Action   string         `json:"action"`            // create、update、delete、upsert
+	Keys     map[string]any `json:"keys,omitempty"`    // 主键，Upsert 时为冲突列，未使用模型时为空
+	Columns  []string       `json:"columns,omitempty"` // 插入或实际变更的列，删除时为空
+	Old      map[string]any `json:"old,omitempty"`     // 变更前的值，插入时为空
+	New      map[string]any `json:"new,omitempty"`     // 变更后的值，删除时为空
+	Operator string         `json:"operator,omitempty"`
+	Time     time.Time      `json:"time"`
+}
+
+// AuditSink 审计记录的写入目标
+// tx 与被审计的语句处于同一事务，返回错误时语句一并回滚
+type AuditSink interface {
+	Write(tx *gorm.DB, changes []Change) error
+}
+
+// AuditSinkFunc 以函数实现 AuditSink
+type AuditSinkFunc func(tx *gorm.DB, changes []Change) error
+
+// Write 调用函数本身
+func (f AuditSinkFunc) Write(tx *gorm.DB, changes []Change) error {
+	return f(tx, changes)
+}
+
+// AuditLog 审计表记录，主键、变更列与新旧值以 JSON 保存
+type AuditLog struct {
+	ID        int64  `gorm:"primaryKey;autoIncrement"`
+	Target    string `gorm:"size:128;index"` // 表名
+	Action    string `gorm:"size:16"`
+	RowKey    string `gorm:"size:512"`
+	Changed   string `gorm:"size:2048"`
+	OldValues string
+	NewValues string
+	Operator  string    `gorm:"size:128"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// AuditTable 写入指定审计表
+type AuditTable string
+
+// Write 批量插入审计表
+func (t AuditTable) Write(tx *gorm.DB, changes []Change) error {
+	logs := make([]AuditLog, 0, len(changes))
+	for _, c := range changes {
+		logs = append(logs, AuditLog{
+			Target: c.Table, Action: c.Action,
+			RowKey: marshal(c.Keys), Changed: marshal(c.Columns),
+			OldValues: marshal(c.Old), NewValues: marshal(c.New),
+			Operator: c.Operator, CreatedAt: c.Time,
+		})
+	}
+	return tx.Table(string(t)).Create(&logs).Error
+}
+
+// 空值保存为空字符串
+func marshal(v any) string {
+	if rv := reflect.ValueOf(v); !rv.IsValid() || rv.Len() == 0 {
+		return ""
+	}
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(bs)
+}
+
+type operatorKey struct{}
+
+// WithOperator 设置审计记录的操作人，需通过 WithContext 传给语句
+func WithOperator(ctx context.Context, operator string) context.Context {
+	return context.WithValue(ctx, operatorKey{}, operator)
+}
+
+// Operator 取得 WithOperator 设置的操作人
+func Operator(ctx context.Context) string {
+	v, _ := ctx.Value(operatorKey{}).(string)
+	return v
+}
+
+const (
+	auditKey     = "dbbase:audit"      // 语句执行前读取的旧值
+	auditTxKey   = "dbbase:audit_tx"   // 由审计开启事务时，开启前的连接
+	auditSkipKey = "dbbase:audit_skip" // 写入审计记录的会话不再审计
+)
+
+// 审计回调，Upsert 通过 upsert 包装写入审计，原生 Exec 语句不审计
+type auditor struct {
+	table  string   // 审计表，不审计自身
+	tables []string // 为空时审计全部表
+	sink   AuditSink
+}
+
+// 语句执行前记录的状态
+type auditState struct {
+	olds []map[string]any
+	set  bool // SET 由审计生成，结束时移除
+}
+
+// 注册审计回调，未设置 AuditSink 时写入 AuditTable 并自动建表
+func (p *DB) registerAudit(db *gorm.DB, cfg Config) error {
+	a := &auditor{tables: cfg.AuditTables, sink: cfg.AuditSink}
+	p.audit = a
+	if a.sink == nil {
+		a.table = cfg.AuditTable
+		if len(a.table) == 0 {
+			a.table = "audit_log"
+		}
+		if err := db.Table(a.table).AutoMigrate(&AuditLog{}); err != nil {
+			return err
+		}
+		a.sink = AuditTable(a.table)
+	}
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().After("gorm:begin_transaction").Before("gorm:before_create").Register("dbbase:audit_begin", a.begin),
+		cb.Create().After("gorm:after_create").Before("gorm:commit_or_rollback_transaction").Register("dbbase:audit_end", a.end(ActionCreate)),
+		cb.Update().After("gorm:begin_transaction").Before("gorm:before_update").Register("dbbase:audit_begin", a.begin),
+		cb.Update().After("gorm:before_update").Before("gorm:update").Register("dbbase:audit_read", a.read(ActionUpdate)),
+		cb.Update().After("gorm:after_update").Before("gorm:commit_or_rollback_transaction").Register("dbbase:audit_end", a.end(ActionUpdate)),
+		cb.Delete().After("gorm:begin_transaction").Before("gorm:before_delete").Register("dbbase:audit_begin", a.begin),
+		cb.Delete().After("gorm:before_delete").Before("gorm:delete").Register("dbbase:audit_read", a.read(ActionDelete)),
+		cb.Delete().After("gorm:after_delete").Before("gorm:commit_or_rollback_transaction").Register("dbbase:audit_end", a.end(ActionDelete)),
+	)
+}
+
+func (a *auditor) enabled(db *gorm.DB) bool {
+	if db.Error != nil || db.DryRun || len(db.Statement.Table) == 0 {
+		return false
+	}
+	if _, ok := db.Get(auditSkipKey); ok {
+		return false
+	}
+	return a.audited(db.Statement.Table)
+}
+
+// 表是否需要审计
+func (a *auditor) audited(table string) bool {
+	if strings.EqualFold(table, a.table) {
+		return false
+	}
+	return len(a.tables) == 0 || slices.ContainsFunc(a.tables, func(t string) bool {
+		return strings.EqualFold(t, table)
+	})
+}
+
+// 不在事务中时开启事务，钩子中的读写同在事务内
+func (a *auditor) begin(db *gorm.DB) {
+	if !a.enabled(db) {
+		return
+	}
+	if tx := db.Begin(); tx.Error == nil {
+		db.InstanceSet(auditTxKey, db.Statement.ConnPool)
+		db.Statement.ConnPool = tx.Statement.ConnPool
+	} else if tx.Error != gorm.ErrInvalidTransaction {
+		_ = db.AddError(tx.Error)
+		return
+	}
+	db.InstanceSet(auditKey, &auditState{})
+}
+
+// 更新与删除在执行前读取旧值
+// gorm 在执行时才生成 SET 并随即移除，此处提前生成以便取得更新的列，同时加入模型的主键条件
+func (a *auditor) read(action string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(auditKey)
+		if !ok || db.Error != nil {
+			return
+		}
+		state := v.(*auditState)
+		stmt := db.Statement
+		if _, ok := stmt.Clauses["SET"]; action == ActionUpdate && !ok && stmt.SQL.Len() == 0 {
+			if set := callbacks.ConvertToAssignments(stmt); len(set) > 0 {
+				stmt.AddClause(set)
+				state.set = true
+			}
+		}
+		conds := auditConds(stmt)
+		if len(conds) == 0 {
+			// 无条件的全表更新或删除不读取旧值
+			return
+		}
+		_ = db.AddError(a.query(db, conds).Find(&state.olds).Error)
+	}
+}
+
+// 写入审计记录，由审计开启的事务在此提交或回滚
+func (a *auditor) end(action string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(auditKey)
+		if !ok {
+			return
+		}
+		state := v.(*auditState)
+		if db.Error == nil && db.RowsAffected > 0 {
+			var changes []Change
+			switch action {
+			case ActionCreate:
+				changes = createChanges(db.Statement)
+			case ActionUpdate:
+				changes = a.updateChanges(db, state.olds)
+			case ActionDelete:
+				changes = deleteChanges(db.Statement, state.olds)
+			}
+			if db.Error == nil && len(changes) > 0 {
+				operator, now := Operator(db.Statement.Context), time.Now()
+				for i := range changes {
+					changes[i].Operator, changes[i].Time = operator, now
+				}
+				_ = db.AddError(a.sink.Write(db.Session(&gorm.Session{NewDB: true}).Set(auditSkipKey, true), changes))
+			}
+		}
+		if state.set {
+			delete(db.Statement.Clauses, "SET")
+		}
+		if pool, ok := db.InstanceGet(auditTxKey); ok {
+			if db.Error != nil {
+				db.Rollback()
+			} else {
+				db.Commit()
+			}
+			db.Statement.ConnPool = pool.(gorm.ConnPool)
+		}
+	}
+}
+
+// 包装 upsert 语句的执行，执行前后按冲突列读取记录并写入审计，需在事务中调用
+// 冲突列均在插入列中时区分插入与更新，否则每行记为 ActionUpsert
+func (a *auditor) upsert(stmt *gorm.Statement, u upsertPlan, exec func(*gorm.DB, [][]any) (int64, error)) func(*gorm.DB, [][]any) (int64, error) {
+	idx := make([]int, 0, len(u.conflict))
+	for _, c := range u.conflict {
+		i := slices.IndexFunc(u.columns, func(col clause.Column) bool { return col.Name == c })
+		if i < 0 {
+			idx = nil
+			break
+		}
+		idx = append(idx, i)
+	}
+	updated := slices.Clone(u.updates)
+	for _, inc := range u.incs {
+		updated = append(updated, inc.column)
+	}
+	return func(tx *gorm.DB, rows [][]any) (int64, error) {
+		var olds, news map[string]map[string]any
+		var err error
+		if len(idx) > 0 {
+			if olds, err = a.lookup(tx, stmt.Table, u.conflict, idx, rows); err != nil {
+				return 0, err
+			}
+		}
+		affected, err := exec(tx, rows)
+		if err != nil || affected == 0 {
+			return affected, err
+		}
+		if len(idx) > 0 {
+			if news, err = a.lookup(tx, stmt.Table, u.conflict, idx, rows); err != nil {
+				return 0, err
+			}
+		}
+		changes := make([]Change, 0, len(rows))
+		seen := map[string]bool{}
+		for _, row := range rows {
+			inserted := make(map[string]any, len(u.columns))
+			for i, c := range u.columns {
+				inserted[c.Name] = row[i]
+			}
+			if len(idx) == 0 {
+				changes = append(changes, Change{Table: stmt.Table, Action: ActionUpsert, Columns: slices.Sorted(maps.Keys(inserted)), New: inserted})
+				continue
+			}
+			key := fmt.Sprint(pick(row, idx))
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			keys := make(map[string]any, len(idx))
+			for i, c := range u.conflict {
+				keys[c] = row[idx[i]]
+			}
+			old, ok := olds[key]
+			if !ok {
+				changes = append(changes, Change{Table: stmt.Table, Action: ActionCreate, Keys: keys, Columns: slices.Sorted(maps.Keys(inserted)), New: inserted})
+				continue
+			}
+			cur := news[key]
+			c := Change{Table: stmt.Table, Action: ActionUpdate, Keys: keys, Old: map[string]any{}, New: map[string]any{}}
+			for _, name := range updated {
+				if slices.Contains(c.Columns, name) || reflect.DeepEqual(old[name], cur[name]) {
+					continue
+				}
+				c.Columns = append(c.Columns, name)
+				c.Old[name], c.New[name] = old[name], cur[name]
+			}
+			if len(c.Columns) > 0 {
+				changes = append(changes, c)
+			}
+		}
+		if len(changes) == 0 {
+			return affected, nil
+		}
+		operator, now := Operator(tx.Statement.Context), time.Now()
+		for i := range changes {
+			changes[i].Operator, changes[i].Time = operator, now
+		}
+		return affected, a.sink.Write(tx.Session(&gorm.Session{NewDB: true}).Set(auditSkipKey, true), changes)
+	}
+}
+
+// 按冲突列读取已有的行，包括已软删除的行，以冲突列的值为键
+func (a *auditor) lookup(tx *gorm.DB, table string, keys []string, idx []int, rows [][]any) (map[string]map[string]any, error) {
+	values := make([][]any, 0, len(rows))
+	for _, row := range rows {
+		values = append(values, pick(row, idx))
+	}
+	column, vals := schema.ToQueryValues(table, keys, values)
+	var list []map[string]any
+	err := tx.Session(&gorm.Session{NewDB: true}).Set(auditSkipKey, true).Table(table).
+		Clauses(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: vals}}}).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]map[string]any, len(list))
+	for _, row := range list {
+		found[fmt.Sprint(rowValues(row, keys))] = row
+	}
+	return found, nil
+}
+
+// 在当前事务中按条件查询被审计表，模型的软删除条件一并生效
+func (a *auditor) query(db *gorm.DB, conds []clause.Expression) *gorm.DB {
+	stmt := db.Statement
+	q := db.Session(&gorm.Session{NewDB: true}).Set(auditSkipKey, true)
+	if stmt.Schema != nil {
+		q = q.Model(reflect.New(stmt.Schema.ModelType).Interface())
+	}
+	if stmt.Unscoped {
+		q = q.Unscoped()
+	}
+	return q.Table(stmt.Table).Clauses(clause.Where{Exprs: conds})
+}
+
+// 语句的查询条件，删除时 gorm 在执行时才追加的模型主键条件在此一并加入
+func auditConds(stmt *gorm.Statement) []clause.Expression {
+	var conds []clause.Expression
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			conds = append(conds, where.Exprs...)
+		}
+	}
+	if stmt.Schema != nil && len(stmt.Schema.PrimaryFields) > 0 && stmt.ReflectValue.IsValid() {
+		switch stmt.ReflectValue.Kind() {
+		case reflect.Struct, reflect.Slice, reflect.Array:
+			_, values := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+			column, vals := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, values)
+			if len(vals) > 0 {
+				conds = append(conds, clause.IN{Column: column, Values: vals})
+			}
+		}
+	}
+	return conds
+}
+
+// 插入的每一行，模型取全部字段，map 取全部键
+func createChanges(stmt *gorm.Statement) []Change {
+	var changes []Change
+	add := func(row map[string]any) {
+		changes = append(changes, Change{
+			Table: stmt.Table, Action: ActionCreate, Keys: rowKeys(stmt, row),
+			Columns: slices.Sorted(maps.Keys(row)), New: row,
+		})
+	}
+	var each func(rv reflect.Value)
+	each = func(rv reflect.Value) {
+		rv = reflect.Indirect(rv)
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := range rv.Len() {
+				each(rv.Index(i))
+			}
+		case reflect.Map:
+			row := make(map[string]any, rv.Len())
+			iter := rv.MapRange()
+			for iter.Next() {
+				name := fmt.Sprint(iter.Key().Interface())
+				if stmt.Schema != nil {
+					if field := stmt.Schema.LookUpField(name); field != nil {
+						name = field.DBName
+					}
+				}
+				row[name] = iter.Value().Interface()
+			}
+			add(row)
+		case reflect.Struct:
+			if stmt.Schema == nil {
+				return
+			}
+			row := make(map[string]any, len(stmt.Schema.DBNames))
+			for _, field := range stmt.Schema.Fields {
+				if len(field.DBName) > 0 && field.Readable {
+					row[field.DBName], _ = field.ValueOf(stmt.Context, rv)
+				}
+			}
+			add(row)
+		}
+	}
+	each(stmt.ReflectValue)
+	return changes
+}
+
+// 按主键重新读取更新后的行，无主键或主键被修改时以 SET 中的值覆盖旧值
+func (a *auditor) updateChanges(db *gorm.DB, olds []map[string]any) []Change {
+	stmt := db.Statement
+	var set clause.Set
+	if c, ok := stmt.Clauses["SET"]; ok {
+		set, _ = c.Expression.(clause.Set)
+	}
+	var pks []string
+	if stmt.Schema != nil {
+		pks = stmt.Schema.PrimaryFieldDBNames
+	}
+	news := map[string]map[string]any{}
+	if len(pks) > 0 && len(olds) > 0 {
+		values := make([][]any, 0, len(olds))
+		for _, old := range olds {
+			values = append(values, rowValues(old, pks))
+		}
+		column, vals := schema.ToQueryValues(stmt.Table, pks, values)
+		var rows []map[string]any
+		if err := a.query(db, []clause.Expression{clause.IN{Column: column, Values: vals}}).Find(&rows).Error; err != nil {
+			_ = db.AddError(err)
+			return nil
+		}
+		for _, row := range rows {
+			news[fmt.Sprint(rowValues(row, pks))] = row
+		}
+	}
+	changes := make([]Change, 0, len(olds))
+	for _, old := range olds {
+		row, ok := news[fmt.Sprint(rowValues(old, pks))]
+		if len(pks) == 0 || !ok {
+			row = maps.Clone(old)
+			for _, v := range set {
+				if _, expr := v.Value.(clause.Expression); !expr {
+					row[v.Column.Name] = v.Value
+				}
+			}
+		}
+		c := Change{Table: stmt.Table, Action: ActionUpdate, Keys: rowKeys(stmt, old), Old: map[string]any{}, New: map[string]any{}}
+		for _, v := range set {
+			name := v.Column.Name
+			if slices.Contains(c.Columns, name) || reflect.DeepEqual(old[name], row[name]) {
+				continue
+			}
+			c.Columns = append(c.Columns, name)
+			c.Old[name], c.New[name] = old[name], row[name]
+		}
+		if len(c.Columns) > 0 {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// 删除的每一行保留全部旧值
+func deleteChanges(stmt *gorm.Statement, olds []map[string]any) []Change {
+	changes := make([]Change, 0, len(olds))
+	for _, old := range olds {
+		changes = append(changes, Change{Table: stmt.Table, Action: ActionDelete, Keys: rowKeys(stmt, old), Old: old})
+	}
+	return changes
+}
+
+// 行中的主键值
+func rowKeys(stmt *gorm.Statement, row map[string]any) map[string]any {
+	if stmt.Schema == nil || len(stmt.Schema.PrimaryFieldDBNames) == 0 {
+		return nil
+	}
+	keys := make(map[string]any, len(stmt.Schema.PrimaryFieldDBNames))
+	for _, name := range stmt.Schema.PrimaryFieldDBNames {
+		keys[name] = row[name]
+	}
+	return keys
+}
+
+// 按下标取出一行中的值
+func pick(row []any, idx []int) []any {
+	values := make([]any, 0, len(idx))
+	for _, i := range idx {
+		values = append(values, row[i])
+	}
+	return values
+}
+
+func rowValues(row map[string]any, names []string) []any {
+	values := make([]any, 0, len(names))
+	for _, name := range names {
+		values = append(values, row[name])
+	}
+	return values
+}
@@ -0,0 +1,220 @@
+package dbbase_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/livexy/plugins/dbbase"
+	sqlite "github.com/livexy/plugins/sqlite/plugin"
+
+	"gorm.io/gorm"
+)
+
+type item struct {
+	ID        int64 `gorm:"primaryKey"`
+	Name      string
+	Qty       int
+	DeletedAt gorm.DeletedAt
+}
+
+// 打开开启审计的 sqlite 库并建表 item
+func openAudit(t *testing.T, cfg dbbase.Config) *dbbase.DB {
+	t.Helper()
+	db := sqlite.New().(*dbbase.DB)
+	cfg.Sources = []string{filepath.Join(t.TempDir(), "test.db")}
+	cfg.Audit = true
+	if _, err := db.InitWithConfig("test", cfg, nil); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := db.DB().AutoMigrate(&item{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// 收集审计记录的 AuditSink
+func collect(changes *[]dbbase.Change) dbbase.AuditSink {
+	return dbbase.AuditSinkFunc(func(tx *gorm.DB, list []dbbase.Change) error {
+		*changes = append(*changes, list...)
+		return nil
+	})
+}
+
+func TestAuditWrites(t *testing.T) {
+	var changes []dbbase.Change
+	db := openAudit(t, dbbase.Config{AuditSink: collect(&changes)})
+	gdb := db.DB().WithContext(dbbase.WithOperator(context.Background(), "tester"))
+
+	it := item{Name: "a", Qty: 1}
+	if err := gdb.Create(&it).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("changes = %+v", changes)
+	}
+	c := changes[0]
+	if c.Table != "item" || c.Action != dbbase.ActionCreate || c.Keys["id"] != it.ID || c.New["name"] != "a" || c.Old != nil || c.Operator != "tester" {
+		t.Fatalf("create = %+v", c)
+	}
+
+	changes = nil
+	if err := gdb.Model(&it).Updates(map[string]any{"qty": 2, "name": "a"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("changes = %+v", changes)
+	}
+	// 只记录实际变更的列
+	c = changes[0]
+	if c.Action != dbbase.ActionUpdate || c.Keys["id"] != it.ID || !slices.Equal(c.Columns, []string{"qty"}) ||
+		c.Old["qty"] != 1 || c.New["qty"] != 2 {
+		t.Fatalf("update = %+v", c)
+	}
+
+	changes = nil
+	if err := gdb.Model(&item{}).Where("name = ?", "a").Update("qty", 2).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("unchanged update = %+v", changes)
+	}
+
+	// 软删除记为删除，保留全部旧值
+	if err := gdb.Delete(&it).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("changes = %+v", changes)
+	}
+	c = changes[0]
+	if c.Action != dbbase.ActionDelete || c.Keys["id"] != it.ID || c.Old["name"] != "a" || c.Old["deleted_at"] != nil || c.New != nil {
+		t.Fatalf("soft delete = %+v", c)
+	}
+
+	changes = nil
+	if err := gdb.Unscoped().Delete(&it).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Action != dbbase.ActionDelete || changes[0].Old["deleted_at"] == nil {
+		t.Fatalf("hard delete = %+v", changes)
+	}
+}
+
+func TestAuditTables(t *testing.T) {
+	var changes []dbbase.Change
+	db := openAudit(t, dbbase.Config{AuditSink: collect(&changes), AuditTables: []string{"ITEM"}})
+	gdb := db.DB()
+	if err := gdb.Exec("create table other (id integer primary key, name text)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := gdb.Table("other").Create(map[string]any{"id": 1, "name": "x"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := gdb.Create(&item{Name: "a"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Table != "item" {
+		t.Fatalf("changes = %+v", changes)
+	}
+}
+
+// AuditSink 返回错误时语句一并回滚
+func TestAuditRollback(t *testing.T) {
+	errSink := errors.New("sink")
+	db := openAudit(t, dbbase.Config{AuditSink: dbbase.AuditSinkFunc(func(*gorm.DB, []dbbase.Change) error {
+		return errSink
+	})})
+	gdb := db.DB()
+	if err := gdb.Create(&item{Name: "a"}).Error; !errors.Is(err, errSink) {
+		t.Fatalf("create err = %v", err)
+	}
+	if err := gdb.Session(&gorm.Session{SkipHooks: true}).Exec("insert into item (id, name, qty) values (1, 'a', 1)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := gdb.Model(&item{ID: 1}).Update("qty", 2).Error; !errors.Is(err, errSink) {
+		t.Fatalf("update err = %v", err)
+	}
+	if err := gdb.Delete(&item{ID: 1}).Error; !errors.Is(err, errSink) {
+		t.Fatalf("delete err = %v", err)
+	}
+	var list []item
+	if err := gdb.Find(&list).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Qty != 1 {
+		t.Fatalf("items = %+v", list)
+	}
+}
+
+// 未设置 AuditSink 时写入默认审计表
+func TestAuditTable(t *testing.T) {
+	db := openAudit(t, dbbase.Config{})
+	gdb := db.DB().WithContext(dbbase.WithOperator(context.Background(), "tester"))
+	it := item{Name: "a", Qty: 1}
+	if err := gdb.Create(&it).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := gdb.Model(&it).Update("qty", 2).Error; err != nil {
+		t.Fatal(err)
+	}
+	var logs []dbbase.AuditLog
+	if err := gdb.Table("audit_log").Order("id").Find(&logs).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("logs = %+v", logs)
+	}
+	l := logs[1]
+	if l.Target != "item" || l.Action != dbbase.ActionUpdate || l.RowKey != `{"id":1}` || l.Changed != `["qty"]` ||
+		l.OldValues != `{"qty":1}` || l.NewValues != `{"qty":2}` || l.Operator != "tester" {
+		t.Fatalf("log = %+v", l)
+	}
+}
+
+func TestAuditUpsert(t *testing.T) {
+	var changes []dbbase.Change
+	db := openAudit(t, dbbase.Config{AuditSink: collect(&changes)})
+	err := db.DB().Exec("create table stock (sku text primary key, qty integer, name text)").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := dbbase.WithOperator(context.Background(), "tester")
+	opt := dbbase.Upsert{Table: "stock", Columns: []string{"sku"}, Increments: map[string]any{"qty": 1}}
+	rows := []map[string]any{{"sku": "a", "qty": 1, "name": "A"}}
+	if _, err = db.Upsert(ctx, rows, opt); err != nil {
+		t.Fatal(err)
+	}
+	rows = []map[string]any{{"sku": "a", "qty": 1, "name": "A"}, {"sku": "b", "qty": 2, "name": "B"}}
+	if _, err = db.Upsert(ctx, rows, opt); err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		action, sku string
+	}{{dbbase.ActionCreate, "a"}, {dbbase.ActionUpdate, "a"}, {dbbase.ActionCreate, "b"}}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %+v", changes)
+	}
+	for i, w := range want {
+		c := changes[i]
+		if c.Action != w.action || c.Keys["sku"] != w.sku || c.Operator != "tester" {
+			t.Fatalf("changes[%d] = %+v, want %s %s", i, c, w.action, w.sku)
+		}
+	}
+	if c := changes[1]; len(c.Columns) != 1 || c.Columns[0] != "qty" || c.Old["qty"] != int64(1) || c.New["qty"] != int64(2) {
+		t.Fatalf("update = %+v", c)
+	}
+
+	// 冲突列不在插入列中时无法区分插入与更新
+	changes = nil
+	_, err = db.Upsert(ctx, []map[string]any{{"qty": 3, "name": "C"}}, dbbase.Upsert{Table: "stock", Columns: []string{"sku"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Action != dbbase.ActionUpsert {
+		t.Fatalf("changes = %+v", changes)
+	}
+}
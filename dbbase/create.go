@@ -0,0 +1,257 @@
+package dbbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	// ErrNotInit 未调用 Init 或初始化失败
+	ErrNotInit = errors.New("数据库未初始化")
+	// ErrMapBatch map 切片无法逐条取得自增 ID
+	ErrMapBatch = errors.New("批量插入并返回 ID 请使用模型切片")
+	// RETURNING 未回填主键
+	errNoReturning = errors.New("未取得主键")
+)
+
+// CreateIDer 插入并返回自增 ID
+type CreateIDer interface {
+	CreateID(ctx context.Context, value any, table, pk string) (int64, error)
+	CreateIDs(ctx context.Context, values any, table, pk string, batchSize int) ([]int64, error)
+}
+
+// GetCreateID 插入数据并获取自增 ID，失败时返回 0，需要错误信息请使用 CreateID
+func (p *DB) GetCreateID(value any, table, pk string) int64 {
+	id, err := p.CreateID(context.Background(), value, table, pk)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// CreateID 在事务中插入单条记录并返回自增 ID，失败时回滚
+// value 为模型指针，或配合 table 使用的 map[string]any；pk 为空时使用模型主键
+func (p *DB) CreateID(ctx context.Context, value any, table, pk string) (int64, error) {
+	ids, err := p.CreateIDs(ctx, value, table, pk, 0)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, gorm.ErrEmptySlice
+	}
+	return ids[0], nil
+}
+
+// CreateIDs 在事务中批量插入并按顺序返回全部自增 ID，失败时整体回滚
+// values 为模型指针、模型切片或其指针，batchSize 大于 0 时分批插入（IDPerRow 方式逐条插入，不生效）
+func (p *DB) CreateIDs(ctx context.Context, values any, table, pk string, batchSize int) ([]int64, error) {
+	if p.db == nil {
+		return nil, ErrNotInit
+	}
+	var ids []int64
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		db := tx
+		if len(table) > 0 {
+			db = tx.Table(table).Session(&gorm.Session{})
+		}
+		if isMap(values) {
+			id, err := p.insertLastID(tx, db, values, pk)
+			ids = []int64{id}
+			return err
+		}
+		field, err := pkField(tx, values, pk)
+		if err != nil {
+			return err
+		}
+		switch p.dialect.IDMode {
+		case IDPerRow:
+			ids, err = p.createPerRow(ctx, tx, db, field, values)
+			return err
+		case IDReturning:
+			db = db.Clauses(clause.Returning{Columns: []clause.Column{{Name: field.DBName}}})
+		}
+		if batchSize > 0 {
+			err = db.CreateInBatches(values, batchSize).Error
+		} else {
+			err = db.Create(values).Error
+		}
+		if err != nil {
+			return err
+		}
+		ids, err = modelIDs(ctx, field, values)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// 插入一条记录并在同一会话中取回自增 ID
+// IDReturning 方言或未提供 LastInsertID 时通过 RETURNING 回填，未回填主键时再以 LastInsertID 兜底
+func (p *DB) insertLastID(tx, db *gorm.DB, value any, pk string) (int64, error) {
+	if p.dialect.IDMode == IDReturning || len(p.dialect.LastInsertID) == 0 {
+		id, err := insertReturning(tx, db, value, pk)
+		if !errors.Is(err, errNoReturning) || len(p.dialect.LastInsertID) == 0 {
+			return id, err
+		}
+		return p.lastInsertID(tx)
+	}
+	err := db.Create(value).Error
+	if err != nil {
+		return 0, err
+	}
+	return p.lastInsertID(tx)
+}
+
+// 在同一会话中执行方言的 LastInsertID 语句
+func (p *DB) lastInsertID(tx *gorm.DB) (int64, error) {
+	var id int64
+	err := tx.Raw(p.dialect.LastInsertID).Scan(&id).Error
+	return id, err
+}
+
+// 插入并读取方言回填的主键，map 未指定 pk 时使用 id
+func insertReturning(tx, db *gorm.DB, value any, pk string) (int64, error) {
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	if rv.Kind() != reflect.Map {
+		field, err := pkField(tx, value, pk)
+		if err != nil {
+			return 0, err
+		}
+		err = db.Clauses(clause.Returning{Columns: []clause.Column{{Name: field.DBName}}}).Create(value).Error
+		if err != nil {
+			return 0, err
+		}
+		return fieldID(tx.Statement.Context, field, rv)
+	}
+	if len(pk) == 0 {
+		pk = "id"
+	}
+	err := db.Clauses(clause.Returning{Columns: []clause.Column{{Name: pk}}}).Create(value).Error
+	if err != nil {
+		return 0, err
+	}
+	v := rv.MapIndex(reflect.ValueOf(pk))
+	if id, ok := intValue(v); ok {
+		return id, nil
+	}
+	return 0, fmt.Errorf("%w %s", errNoReturning, pk)
+}
+
+// 逐条插入并回填主键
+func (p *DB) createPerRow(ctx context.Context, tx, db *gorm.DB, field *schema.Field, values any) ([]int64, error) {
+	rv := reflect.Indirect(reflect.ValueOf(values))
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		id, err := p.insertLastID(tx, db, values, field.DBName)
+		if err != nil {
+			return nil, err
+		}
+		return []int64{id}, field.Set(ctx, rv, id)
+	}
+	ids := make([]int64, 0, rv.Len())
+	for i := range rv.Len() {
+		elem := rv.Index(i)
+		if elem.Kind() != reflect.Pointer {
+			if !elem.CanAddr() {
+				return nil, errors.New("无法回填主键，请传入切片或数组指针")
+			}
+			elem = elem.Addr()
+		}
+		id, err := p.insertLastID(tx, db, elem.Interface(), field.DBName)
+		if err != nil {
+			return nil, err
+		}
+		err = field.Set(ctx, elem.Elem(), id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// 是否为 map 参数，map 切片不支持
+func isMap(values any) bool {
+	rv := reflect.Indirect(reflect.ValueOf(values))
+	return rv.Kind() == reflect.Map
+}
+
+// 解析模型的主键字段
+func pkField(db *gorm.DB, values any, pk string) (*schema.Field, error) {
+	rv := reflect.Indirect(reflect.ValueOf(values))
+	if (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem().Kind() == reflect.Map {
+		return nil, ErrMapBatch
+	}
+	stmt := &gorm.Statement{DB: db}
+	err := stmt.Parse(values)
+	if err != nil {
+		return nil, err
+	}
+	field := stmt.Schema.PrioritizedPrimaryField
+	if len(pk) > 0 {
+		field = stmt.Schema.LookUpField(pk)
+	}
+	if field == nil && len(pk) > 0 {
+		// 部分方言的列名为大写，忽略大小写再查找一次
+		for _, f := range stmt.Schema.Fields {
+			if strings.EqualFold(f.DBName, pk) {
+				field = f
+				break
+			}
+		}
+	}
+	if field == nil {
+		return nil, fmt.Errorf("模型 %s 缺少主键字段 %s", stmt.Schema.Name, pk)
+	}
+	return field, nil
+}
+
+// 读取模型中回填的主键
+func modelIDs(ctx context.Context, field *schema.Field, values any) ([]int64, error) {
+	rv := reflect.Indirect(reflect.ValueOf(values))
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		id, err := fieldID(ctx, field, rv)
+		return []int64{id}, err
+	}
+	ids := make([]int64, 0, rv.Len())
+	for i := range rv.Len() {
+		id, err := fieldID(ctx, field, reflect.Indirect(rv.Index(i)))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func fieldID(ctx context.Context, field *schema.Field, rv reflect.Value) (int64, error) {
+	val, _ := field.ValueOf(ctx, rv)
+	if id, ok := intValue(reflect.ValueOf(val)); ok {
+		return id, nil
+	}
+	return 0, fmt.Errorf("主键字段 %s 不是整数类型", field.Name)
+}
+
+// 转换整数值，接口与指针会先解引用
+func intValue(v reflect.Value) (int64, bool) {
+	for v.IsValid() && (v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer) {
+		v = v.Elem()
+	}
+	switch {
+	case !v.IsValid():
+		return 0, false
+	case v.CanInt():
+		return v.Int(), true
+	case v.CanUint():
+		return int64(v.Uint()), true
+	}
+	return 0, false
+}
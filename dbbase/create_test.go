@@ -0,0 +1,53 @@
+package dbbase_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/livexy/plugin/dber"
+	"github.com/livexy/plugins/dbbase"
+	sqlite "github.com/livexy/plugins/sqlite/plugin"
+
+	"gorm.io/gorm"
+)
+
+func TestCreateIDMap(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "test.db")
+	db := sqlite.New().(*dbbase.DB)
+	_, err := db.Init("test", dber.DBConfig{Sources: []string{dsn}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	gdb := db.DB()
+	err = gdb.Exec("create table user (id integer primary key autoincrement, name text)").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sqls []string
+	err = gdb.Callback().Create().After("gorm:create").Register("test:sql", func(tx *gorm.DB) {
+		sqls = append(sqls, tx.Statement.SQL.String())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"a", "b"} {
+		id, cerr := db.CreateID(context.Background(), map[string]any{"name": name}, "user", "")
+		if cerr != nil {
+			t.Fatal(cerr)
+		}
+		if id != int64(i+1) {
+			t.Fatalf("id = %d, want %d", id, i+1)
+		}
+	}
+	for _, sql := range sqls {
+		if !strings.Contains(strings.ToUpper(sql), "RETURNING") {
+			t.Fatalf("map insert without RETURNING: %s", sql)
+		}
+	}
+	if len(sqls) != 2 {
+		t.Fatalf("insert count = %d, want 2", len(sqls))
+	}
+}
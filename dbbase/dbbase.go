@@ -0,0 +1,252 @@
+// Package dbbase 数据库插件的通用 dber 实现
+// 各数据库插件只需提供方言描述，连接、读写分离、连接池与插入取 ID 等逻辑统一在此实现
+package dbbase
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/livexy/plugin/dber"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+	"gorm.io/plugin/dbresolver"
+)
+
+// IDMode 插入后取回自增 ID 的方式
+type IDMode int
+
+const (
+	// IDLastInsert 驱动按 LastInsertId 回填模型，map 通过 LastInsertID 语句取回
+	IDLastInsert IDMode = iota
+	// IDReturning 通过 RETURNING 子句回填模型与 map，map 未回填时以 LastInsertID 语句兜底
+	IDReturning
+	// IDPerRow 逐条插入并通过 LastInsertID 语句取回后回填模型
+	IDPerRow
+)
+
+// Dialect 数据库方言描述
+type Dialect struct {
+	Name  string                          // 数据库名称
+	Open  func(dsn string) gorm.Dialector // 按 DSN 创建 gorm 方言
+	Slots int                             // 单条语句允许的最大占位符数
+
+	IfNull      string                             // 空值判断函数名
+	If          string                             // 条件判断函数名，以 name(cond, a, b) 调用，数据库没有该函数时留空
+	GroupConcat func(field string) string          // 字符串聚合表达式
+	ExAdd       func(field string, val any) any    // 字段自增表达式，为空时使用 field+?
+	ClobScan    func(clob *dber.Clob, v any) error // CLOB 扫描，为空时忽略
+	Helper      Helper                             // 日期、字符串、分页等 SQL 片段
+
+	IDMode       IDMode // 取回自增 ID 的方式
+	LastInsertID string // 同一会话中取回最近自增 ID 的语句
+
+	Upsert      UpsertMode // 插入冲突时更新的语法
+	DualTable   string     // 无表 SELECT 使用的虚表，如 DUAL
+	MergeSuffix string     // MERGE 语句的结尾，SQL Server 需要分号
+}
+
+// Config 数据库配置，在 dber.DBConfig 基础上增加读写分离策略、按表路由、连接时长与副本健康检查
+type Config struct {
+	dber.DBConfig   `yaml:",inline"`
+	Policy          string        `yaml:"policy"`          // 读写分离策略：random（默认）、roundrobin、weighted、latency，其他值初始化时报错
+	Weights         []int         `yaml:"weights"`         // weighted 策略下各副本的权重
+	ConnMaxIdleTime time.Duration `yaml:"connMaxIdleTime"` // 空闲连接最长保留时间，0 不限制
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime"` // 连接最长使用时间，默认 1 小时
+	HealthCheck     time.Duration `yaml:"healthCheck"`     // 副本健康检查间隔，0 不检查
+	HealthTimeout   time.Duration `yaml:"healthTimeout"`   // 单次检查超时，默认 3 秒
+	Routes          []Route       `yaml:"routes"`          // 按表或模型路由
+	SlowThreshold   time.Duration `yaml:"slowThreshold"`   // 超过该耗时的语句以 Warn 记录到日志，0 不记录
+	Audit           bool          `yaml:"audit"`           // 记录增删改与 Upsert 审计，未设置 AuditSink 时写入 AuditTable，原生 Exec 语句不审计
+	AuditTable      string        `yaml:"auditTable"`      // 审计表名，默认 audit_log，不存在时自动创建
+	AuditTables     []string      `yaml:"auditTables"`     // 仅审计这些表，为空时审计全部表
+	AuditSink       AuditSink     `yaml:"-"`               // 自定义审计写入目标
+}
+
+// Dber 扩展的数据库接口，插件实例可断言为该接口
+type Dber interface {
+	dber.Dber
+	InitWithConfig(logname string, cfg Config, val any) (any, error)
+	DB() *gorm.DB
+	Helper() Helper
+	ReplicaStats() []ReplicaStat
+	UseMetrics(metrics Metrics, tracer Tracer, interval time.Duration)
+	PoolStats() map[string]sql.DBStats
+	Close() error
+	CreateIDer
+	Upserter
+}
+
+// DB 通用 dber 实现
+type DB struct {
+	dialect    Dialect
+	db         *gorm.DB
+	primaryDSN string
+	replicas   []*replica
+	pools      []gorm.ConnPool
+	stop       chan struct{}
+	monitor    monitor
+	audit      *auditor
+}
+
+var _ Dber = (*DB)(nil)
+
+// New 按方言描述创建数据库适配实例
+func New(dialect Dialect) *DB {
+	return &DB{dialect: dialect}
+}
+
+// NewWithDB 以已打开的 gorm 连接创建实例，不注册读写分离、监控与审计
+// 可用于复用外部连接，或配合 DryRun 生成各方言的 SQL
+func NewWithDB(dialect Dialect, db *gorm.DB) *DB {
+	return &DB{dialect: dialect, db: db}
+}
+
+// Init 初始化数据库连接
+// 第一个 Sources 为主库，其余 Sources 与 Replicas 通过 dbresolver 注册为读写分离
+func (p *DB) Init(logname string, dbconf dber.DBConfig, val any) (any, error) {
+	return p.InitWithConfig(logname, Config{DBConfig: dbconf}, val)
+}
+
+// InitWithConfig 按扩展配置初始化数据库连接
+// 开启健康检查后，连续失败的副本不再参与读取，恢复后重新加入，副本全部不可用时读取主库
+func (p *DB) InitWithConfig(logname string, cfg Config, val any) (any, error) {
+	if len(cfg.Sources) == 0 {
+		return nil, errors.New("请在config.yaml中配置数据库sources")
+	}
+	if err := checkPolicy(cfg.Policy, cfg.HealthCheck > 0); err != nil {
+		return nil, err
+	}
+	for _, route := range cfg.Routes {
+		if err := checkPolicy(route.Policy, cfg.HealthCheck > 0); err != nil {
+			return nil, fmt.Errorf("route %s：%w", cmp.Or(route.Name, strings.Join(route.Tables, ",")), err)
+		}
+	}
+	if cfg.ConnMaxLifetime == 0 {
+		cfg.ConnMaxLifetime = time.Hour
+	}
+	if cfg.HealthTimeout == 0 {
+		cfg.HealthTimeout = 3 * time.Second
+	}
+	var l logger.Interface
+	if v, ok := val.(logger.Interface); ok {
+		l = v
+	}
+	db, err := gorm.Open(p.dialect.Open(cfg.Sources[0]), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+		SkipDefaultTransaction: true,
+		PrepareStmt:            true,
+		Logger:                 l,
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.primaryDSN = cfg.Sources[0]
+	p.monitor.slow = cfg.SlowThreshold
+	p.monitor.names = map[gorm.ConnPool]string{}
+	if sqlDB, e := db.DB(); e == nil {
+		p.pools = append(p.pools, sqlDB)
+		p.monitor.names[sqlDB] = "primary"
+	}
+	health := cfg.HealthCheck > 0
+	resolver := dbresolver.Register(p.resolverConfig("default", cfg.Policy, cfg.Weights, cfg.Sources[1:], cfg.Replicas, health))
+	for _, route := range cfg.Routes {
+		datas := make([]any, 0, len(route.Tables)+len(route.Models))
+		for _, v := range route.Tables {
+			datas = append(datas, v)
+		}
+		datas = append(datas, route.Models...)
+		if len(datas) == 0 {
+			continue
+		}
+		name := route.Name
+		if len(name) == 0 {
+			name = strings.Join(route.Tables, ",")
+		}
+		policy := route.Policy
+		if len(policy) == 0 {
+			policy = cfg.Policy
+		}
+		resolver = resolver.Register(p.resolverConfig(name, policy, route.Weights, route.Sources, route.Replicas, health), datas...)
+	}
+	err = db.Use(resolver.
+		SetMaxIdleConns(cfg.MaxIdleConns).
+		SetMaxOpenConns(cfg.MaxOpenConns).
+		SetConnMaxIdleTime(cfg.ConnMaxIdleTime).
+		SetConnMaxLifetime(cfg.ConnMaxLifetime))
+	if err == nil {
+		err = p.registerMetrics(db)
+	}
+	if err == nil && cfg.Audit {
+		err = p.registerAudit(db, cfg)
+	}
+	if err != nil {
+		_ = p.Close()
+		return nil, err
+	}
+	p.db = db
+	if health && len(p.replicas) > 0 {
+		p.stop = make(chan struct{})
+		go p.healthCheck(cfg.HealthCheck, cfg.HealthTimeout, p.stop)
+	}
+	return db, nil
+}
+
+// DB 返回 Init 创建的连接，未初始化时为 nil
+func (p *DB) DB() *gorm.DB {
+	return p.db
+}
+
+// Dialect 返回方言描述
+func (p *DB) Dialect() Dialect {
+	return p.dialect
+}
+
+// Helper 返回生成 SQL 片段的方言工具
+func (p *DB) Helper() Helper {
+	return p.dialect.Helper
+}
+
+// ExAdd 返回用于 GORM 的字段自增表达式
+func (p *DB) ExAdd(field string, val any) any {
+	if p.dialect.ExAdd != nil {
+		return p.dialect.ExAdd(field, val)
+	}
+	return gorm.Expr(field+`+?`, val)
+}
+
+// IfNull 返回空值判断函数名
+func (p *DB) IfNull() string {
+	return p.dialect.IfNull
+}
+
+// If 返回条件判断函数名，以 name(cond, a, b) 调用
+// 数据库没有该函数时返回空字符串（如 Oracle），此时拼接的 SQL 无效，可移植的代码请使用 Helper().Cond
+func (p *DB) If() string {
+	return p.dialect.If
+}
+
+// GroupConcat 返回字符串聚合表达式
+func (p *DB) GroupConcat(field string) string {
+	return p.dialect.GroupConcat(field)
+}
+
+// GetSlots 获取数据库支持的最大插槽数
+func (p *DB) GetSlots() int {
+	return p.dialect.Slots
+}
+
+// ClobScan 处理 CLOB 类型的扫描
+func (p *DB) ClobScan(clob *dber.Clob, v any) error {
+	if p.dialect.ClobScan != nil {
+		return p.dialect.ClobScan(clob, v)
+	}
+	return nil
+}
@@ -0,0 +1,535 @@
+package dbbase
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Unit 日期时间单位
+type Unit int
+
+const (
+	Year Unit = iota
+	Month
+	Day
+	Hour
+	Minute
+	Second
+)
+
+var unitNames = [...]string{Year: "YEAR", Month: "MONTH", Day: "DAY", Hour: "HOUR", Minute: "MINUTE", Second: "SECOND"}
+
+// String 单位的大写名称，超出范围时返回 Unit(n)
+func (u Unit) String() string {
+	if u < Year || u > Second {
+		return "Unit(" + strconv.Itoa(int(u)) + ")"
+	}
+	return unitNames[u]
+}
+
+// 按单位取出写法，超出范围时返回 String()，生成的语句由数据库报错而不会 panic
+func (u Unit) in(names []string) string {
+	if u < 0 || int(u) >= len(names) || len(names[u]) == 0 {
+		return u.String()
+	}
+	return names[u]
+}
+
+// 各单位对应的秒数，year 与 month 不按秒换算
+var unitSeconds = [...]int{Year: 0, Month: 0, Day: 86400, Hour: 3600, Minute: 60, Second: 1}
+
+// 每单位的秒数，仅 day 及以下有效，否则返回 String()
+func (u Unit) seconds() string {
+	if u < Day || u > Second {
+		return u.String()
+	}
+	return strconv.Itoa(unitSeconds[u])
+}
+
+// 每天包含的单位数，仅 day 及以下有效，否则返回 String()
+func (u Unit) perDay() string {
+	if u < Day || u > Second {
+		return u.String()
+	}
+	return strconv.Itoa(86400 / unitSeconds[u])
+}
+
+// Helper 生成各数据库通用写法的 SQL 片段
+// field、start、end 等参数为列名或表达式，原样拼接；sep、path 等字面量由实现负责转义
+// unit 超出范围时以 Unit(n) 写入语句，由数据库返回错误
+type Helper interface {
+	// DateTrunc 将时间截断到指定单位
+	DateTrunc(unit Unit, field string) string
+	// DateFormat 按 YYYY、MM、DD、HH（24 小时）、mm、ss 组成的格式输出字符串
+	DateFormat(field, layout string) string
+	// DateAdd 时间加上 n 个单位，n 可为负数
+	DateAdd(field string, n int, unit Unit) string
+	// DateDiff 返回 end 减 start 的整单位数，SQL Server、DM 与 SQLite 的 year、month 按跨越的边界计数
+	DateDiff(unit Unit, start, end string) string
+	// Concat 字符串拼接，NULL 视为空字符串
+	Concat(parts ...string) string
+	// Substring 截取子串，start 从 1 开始，length 小于等于 0 时截取到末尾
+	Substring(field string, start, length int) string
+	// ILike 忽略大小写的 LIKE 条件，包含一个 ? 占位符
+	ILike(field string) string
+	// JSONExtract 以文本形式取出 JSON 字段，path 为以 . 分隔的键名
+	JSONExtract(field, path string) string
+	// RandomOrder 随机排序表达式
+	RandomOrder() string
+	// Bool 布尔字面量
+	Bool(v bool) string
+	// GroupConcatSep 指定分隔符与排序的字符串聚合，order 为空时不排序
+	GroupConcatSep(field, sep, order string) string
+	// Limit 分页子句，limit 小于等于 0 时不限制条数
+	Limit(limit, offset int) string
+	// Cond 条件表达式，各数据库均生成 CASE WHEN，方言的 If() 为空时使用
+	Cond(cond, then, els string) string
+}
+
+// 标准 SQL 字符串字面量
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// 转换 DateFormat 的格式
+func layoutReplacer(year, month, day, hour, minute, second string) *strings.Replacer {
+	return strings.NewReplacer("YYYY", year, "MM", month, "DD", day, "HH", hour, "mm", minute, "ss", second)
+}
+
+// MySQLHelper MySQL 的 SQL 片段
+type MySQLHelper struct{}
+
+var (
+	mysqlTrunc   = [...]string{Year: "%Y-01-01 00:00:00", Month: "%Y-%m-01 00:00:00", Day: "%Y-%m-%d 00:00:00", Hour: "%Y-%m-%d %H:00:00", Minute: "%Y-%m-%d %H:%i:00", Second: "%Y-%m-%d %H:%i:%s"}
+	mysqlLayout  = layoutReplacer("%Y", "%m", "%d", "%H", "%i", "%s")
+	mysqlEscaper = strings.NewReplacer(`\`, `\\`, "'", "''")
+)
+
+// MySQL 默认允许反斜杠转义
+func (MySQLHelper) quote(s string) string {
+	return "'" + mysqlEscaper.Replace(s) + "'"
+}
+
+func (MySQLHelper) DateTrunc(unit Unit, field string) string {
+	return "CAST(DATE_FORMAT(" + field + ", '" + unit.in(mysqlTrunc[:]) + "') AS DATETIME)"
+}
+
+func (h MySQLHelper) DateFormat(field, layout string) string {
+	return "DATE_FORMAT(" + field + ", " + h.quote(mysqlLayout.Replace(layout)) + ")"
+}
+
+func (MySQLHelper) DateAdd(field string, n int, unit Unit) string {
+	return "DATE_ADD(" + field + ", INTERVAL " + strconv.Itoa(n) + " " + unit.String() + ")"
+}
+
+func (MySQLHelper) DateDiff(unit Unit, start, end string) string {
+	return "TIMESTAMPDIFF(" + unit.String() + ", " + start + ", " + end + ")"
+}
+
+func (MySQLHelper) Concat(parts ...string) string {
+	return "CONCAT_WS(''" + prefixJoin(", ", parts) + ")"
+}
+
+func (MySQLHelper) Substring(field string, start, length int) string {
+	if length <= 0 {
+		return "SUBSTRING(" + field + ", " + strconv.Itoa(start) + ")"
+	}
+	return "SUBSTRING(" + field + ", " + strconv.Itoa(start) + ", " + strconv.Itoa(length) + ")"
+}
+
+func (MySQLHelper) ILike(field string) string {
+	return "LOWER(" + field + ") LIKE LOWER(?)"
+}
+
+func (h MySQLHelper) JSONExtract(field, path string) string {
+	return "JSON_UNQUOTE(JSON_EXTRACT(" + field + ", " + h.quote("$."+path) + "))"
+}
+
+func (MySQLHelper) RandomOrder() string {
+	return "RAND()"
+}
+
+func (MySQLHelper) Bool(v bool) string {
+	return boolNumber(v)
+}
+
+func (h MySQLHelper) GroupConcatSep(field, sep, order string) string {
+	s := "GROUP_CONCAT(" + field
+	if len(order) > 0 {
+		s += " ORDER BY " + order
+	}
+	return s + " SEPARATOR " + h.quote(sep) + ")"
+}
+
+func (MySQLHelper) Limit(limit, offset int) string {
+	return limitOffset(limit, offset, "18446744073709551615")
+}
+
+func (MySQLHelper) Cond(cond, then, els string) string {
+	return caseWhen(cond, then, els)
+}
+
+// PostgresHelper PostgreSQL 及兼容数据库（openGauss、Kingbase）的 SQL 片段
+type PostgresHelper struct{}
+
+var (
+	postgresUnits  = [...]string{Year: "year", Month: "month", Day: "day", Hour: "hour", Minute: "minute", Second: "second"}
+	postgresLayout = layoutReplacer("YYYY", "MM", "DD", "HH24", "MI", "SS")
+)
+
+func (PostgresHelper) DateTrunc(unit Unit, field string) string {
+	return "date_trunc('" + unit.in(postgresUnits[:]) + "', " + field + ")"
+}
+
+func (PostgresHelper) DateFormat(field, layout string) string {
+	return "to_char(" + field + ", " + quote(postgresLayout.Replace(layout)) + ")"
+}
+
+func (PostgresHelper) DateAdd(field string, n int, unit Unit) string {
+	return "(" + field + " + interval '" + strconv.Itoa(n) + " " + unit.in(postgresUnits[:]) + "')"
+}
+
+func (PostgresHelper) DateDiff(unit Unit, start, end string) string {
+	switch unit {
+	case Year:
+		return "cast(date_part('year', age(" + end + ", " + start + ")) as bigint)"
+	case Month:
+		return "cast(date_part('year', age(" + end + ", " + start + ")) * 12 + date_part('month', age(" + end + ", " + start + ")) as bigint)"
+	}
+	return "cast(trunc(extract(epoch from (" + end + ") - (" + start + ")) / " + unit.seconds() + ") as bigint)"
+}
+
+func (PostgresHelper) Concat(parts ...string) string {
+	return "concat(" + strings.Join(parts, ", ") + ")"
+}
+
+func (PostgresHelper) Substring(field string, start, length int) string {
+	if length <= 0 {
+		return "substr(" + field + ", " + strconv.Itoa(start) + ")"
+	}
+	return "substr(" + field + ", " + strconv.Itoa(start) + ", " + strconv.Itoa(length) + ")"
+}
+
+func (PostgresHelper) ILike(field string) string {
+	return field + " ILIKE ?"
+}
+
+func (PostgresHelper) JSONExtract(field, path string) string {
+	return "cast(" + field + " as jsonb) #>> " + quote("{"+strings.ReplaceAll(path, ".", ",")+"}")
+}
+
+func (PostgresHelper) RandomOrder() string {
+	return "random()"
+}
+
+func (PostgresHelper) Bool(v bool) string {
+	return strconv.FormatBool(v)
+}
+
+func (PostgresHelper) GroupConcatSep(field, sep, order string) string {
+	s := "string_agg(cast(" + field + " as text), " + quote(sep)
+	if len(order) > 0 {
+		s += " ORDER BY " + order
+	}
+	return s + ")"
+}
+
+func (PostgresHelper) Limit(limit, offset int) string {
+	return limitOffset(limit, offset, "ALL")
+}
+
+func (PostgresHelper) Cond(cond, then, els string) string {
+	return caseWhen(cond, then, els)
+}
+
+// OracleHelper Oracle 的 SQL 片段，要求 12c 及以上版本
+type OracleHelper struct{}
+
+var (
+	oracleTrunc  = [...]string{Year: "YYYY", Month: "MM", Day: "DD", Hour: "HH24", Minute: "MI"}
+	oracleLayout = postgresLayout
+)
+
+func (OracleHelper) DateTrunc(unit Unit, field string) string {
+	if unit == Second {
+		return "CAST(" + field + " AS TIMESTAMP(0))"
+	}
+	return "TRUNC(" + field + ", '" + unit.in(oracleTrunc[:]) + "')"
+}
+
+func (OracleHelper) DateFormat(field, layout string) string {
+	return "TO_CHAR(" + field + ", " + quote(oracleLayout.Replace(layout)) + ")"
+}
+
+func (OracleHelper) DateAdd(field string, n int, unit Unit) string {
+	switch unit {
+	case Year:
+		return "ADD_MONTHS(" + field + ", " + strconv.Itoa(n*12) + ")"
+	case Month:
+		return "ADD_MONTHS(" + field + ", " + strconv.Itoa(n) + ")"
+	}
+	return "(" + field + " + NUMTODSINTERVAL(" + strconv.Itoa(n) + ", '" + unit.String() + "'))"
+}
+
+func (OracleHelper) DateDiff(unit Unit, start, end string) string {
+	switch unit {
+	case Year:
+		return "TRUNC(MONTHS_BETWEEN(" + end + ", " + start + ") / 12)"
+	case Month:
+		return "TRUNC(MONTHS_BETWEEN(" + end + ", " + start + "))"
+	}
+	return "TRUNC((CAST(" + end + " AS DATE) - CAST(" + start + " AS DATE)) * " + unit.perDay() + ")"
+}
+
+func (OracleHelper) Concat(parts ...string) string {
+	return "(" + strings.Join(parts, " || ") + ")"
+}
+
+func (OracleHelper) Substring(field string, start, length int) string {
+	if length <= 0 {
+		return "SUBSTR(" + field + ", " + strconv.Itoa(start) + ")"
+	}
+	return "SUBSTR(" + field + ", " + strconv.Itoa(start) + ", " + strconv.Itoa(length) + ")"
+}
+
+func (OracleHelper) ILike(field string) string {
+	return "LOWER(" + field + ") LIKE LOWER(?)"
+}
+
+func (OracleHelper) JSONExtract(field, path string) string {
+	return "JSON_VALUE(" + field + ", " + quote("$."+path) + ")"
+}
+
+func (OracleHelper) RandomOrder() string {
+	return "DBMS_RANDOM.VALUE"
+}
+
+func (OracleHelper) Bool(v bool) string {
+	return boolNumber(v)
+}
+
+func (OracleHelper) GroupConcatSep(field, sep, order string) string {
+	if len(order) == 0 {
+		order = "NULL"
+	}
+	return "LISTAGG(" + field + ", " + quote(sep) + ") WITHIN GROUP (ORDER BY " + order + ")"
+}
+
+func (OracleHelper) Limit(limit, offset int) string {
+	return offsetFetch(limit, offset)
+}
+
+func (OracleHelper) Cond(cond, then, els string) string {
+	return caseWhen(cond, then, els)
+}
+
+// DamengHelper 达梦的 SQL 片段，与 Oracle 写法基本一致，日期加减使用达梦自带的函数
+type DamengHelper struct {
+	OracleHelper
+}
+
+func (DamengHelper) DateAdd(field string, n int, unit Unit) string {
+	return "DATEADD(" + unit.String() + ", " + strconv.Itoa(n) + ", " + field + ")"
+}
+
+func (DamengHelper) DateDiff(unit Unit, start, end string) string {
+	return "DATEDIFF(" + unit.String() + ", " + start + ", " + end + ")"
+}
+
+func (DamengHelper) RandomOrder() string {
+	return "RAND()"
+}
+
+// SQLServerHelper SQL Server 的 SQL 片段，要求 2017 及以上版本
+type SQLServerHelper struct{}
+
+var sqlserverLayout = layoutReplacer("yyyy", "MM", "dd", "HH", "mm", "ss")
+
+func (SQLServerHelper) DateTrunc(unit Unit, field string) string {
+	if unit == Second {
+		// 以 0 为基准按秒计算会溢出
+		return "DATEADD(SECOND, DATEDIFF(SECOND, '2000-01-01', " + field + "), CAST('2000-01-01' AS DATETIME2))"
+	}
+	return "DATEADD(" + unit.String() + ", DATEDIFF(" + unit.String() + ", 0, " + field + "), CAST(0 AS DATETIME))"
+}
+
+func (SQLServerHelper) DateFormat(field, layout string) string {
+	return "FORMAT(" + field + ", " + quote(sqlserverLayout.Replace(layout)) + ")"
+}
+
+func (SQLServerHelper) DateAdd(field string, n int, unit Unit) string {
+	return "DATEADD(" + unit.String() + ", " + strconv.Itoa(n) + ", " + field + ")"
+}
+
+func (SQLServerHelper) DateDiff(unit Unit, start, end string) string {
+	return "DATEDIFF(" + unit.String() + ", " + start + ", " + end + ")"
+}
+
+func (SQLServerHelper) Concat(parts ...string) string {
+	return "CONCAT(''" + prefixJoin(", ", parts) + ")"
+}
+
+func (SQLServerHelper) Substring(field string, start, length int) string {
+	if length <= 0 {
+		length = math.MaxInt32
+	}
+	return "SUBSTRING(" + field + ", " + strconv.Itoa(start) + ", " + strconv.Itoa(length) + ")"
+}
+
+func (SQLServerHelper) ILike(field string) string {
+	return "LOWER(" + field + ") LIKE LOWER(?)"
+}
+
+func (SQLServerHelper) JSONExtract(field, path string) string {
+	return "JSON_VALUE(" + field + ", " + quote("$."+path) + ")"
+}
+
+func (SQLServerHelper) RandomOrder() string {
+	return "NEWID()"
+}
+
+func (SQLServerHelper) Bool(v bool) string {
+	return boolNumber(v)
+}
+
+func (SQLServerHelper) GroupConcatSep(field, sep, order string) string {
+	s := "STRING_AGG(CAST(" + field + " AS NVARCHAR(MAX)), " + quote(sep) + ")"
+	if len(order) > 0 {
+		s += " WITHIN GROUP (ORDER BY " + order + ")"
+	}
+	return s
+}
+
+// Limit 需要查询中已有 ORDER BY
+func (SQLServerHelper) Limit(limit, offset int) string {
+	return offsetFetch(limit, offset)
+}
+
+func (SQLServerHelper) Cond(cond, then, els string) string {
+	return caseWhen(cond, then, els)
+}
+
+// SQLiteHelper SQLite 的 SQL 片段
+type SQLiteHelper struct{}
+
+var (
+	sqliteUnits  = [...]string{Year: "years", Month: "months", Day: "days", Hour: "hours", Minute: "minutes", Second: "seconds"}
+	sqliteTrunc  = [...]string{Year: "%Y-01-01 00:00:00", Month: "%Y-%m-01 00:00:00", Day: "%Y-%m-%d 00:00:00", Hour: "%Y-%m-%d %H:00:00", Minute: "%Y-%m-%d %H:%M:00", Second: "%Y-%m-%d %H:%M:%S"}
+	sqliteLayout = layoutReplacer("%Y", "%m", "%d", "%H", "%M", "%S")
+)
+
+func (SQLiteHelper) DateTrunc(unit Unit, field string) string {
+	return "strftime('" + unit.in(sqliteTrunc[:]) + "', " + field + ")"
+}
+
+func (SQLiteHelper) DateFormat(field, layout string) string {
+	return "strftime(" + quote(sqliteLayout.Replace(layout)) + ", " + field + ")"
+}
+
+func (SQLiteHelper) DateAdd(field string, n int, unit Unit) string {
+	sign := "+"
+	if n < 0 {
+		sign = ""
+	}
+	return "datetime(" + field + ", '" + sign + strconv.Itoa(n) + " " + unit.in(sqliteUnits[:]) + "')"
+}
+
+func (SQLiteHelper) DateDiff(unit Unit, start, end string) string {
+	years := "(cast(strftime('%Y', " + end + ") as integer) - cast(strftime('%Y', " + start + ") as integer))"
+	switch unit {
+	case Year:
+		return years
+	case Month:
+		return "(" + years + " * 12 + cast(strftime('%m', " + end + ") as integer) - cast(strftime('%m', " + start + ") as integer))"
+	}
+	return "cast((julianday(" + end + ") - julianday(" + start + ")) * " + unit.perDay() + " as integer)"
+}
+
+func (SQLiteHelper) Concat(parts ...string) string {
+	items := make([]string, len(parts))
+	for i, v := range parts {
+		items[i] = "ifnull(" + v + ", '')"
+	}
+	return "(" + strings.Join(items, " || ") + ")"
+}
+
+func (SQLiteHelper) Substring(field string, start, length int) string {
+	if length <= 0 {
+		return "substr(" + field + ", " + strconv.Itoa(start) + ")"
+	}
+	return "substr(" + field + ", " + strconv.Itoa(start) + ", " + strconv.Itoa(length) + ")"
+}
+
+// ILike SQLite 的 LIKE 对 ASCII 字符不区分大小写
+func (SQLiteHelper) ILike(field string) string {
+	return field + " LIKE ?"
+}
+
+func (SQLiteHelper) JSONExtract(field, path string) string {
+	return "json_extract(" + field + ", " + quote("$."+path) + ")"
+}
+
+func (SQLiteHelper) RandomOrder() string {
+	return "random()"
+}
+
+func (SQLiteHelper) Bool(v bool) string {
+	return boolNumber(v)
+}
+
+// GroupConcatSep SQLite 3.44 以下不支持聚合内排序，order 被忽略
+func (SQLiteHelper) GroupConcatSep(field, sep, order string) string {
+	return "group_concat(" + field + ", " + quote(sep) + ")"
+}
+
+func (SQLiteHelper) Limit(limit, offset int) string {
+	return limitOffset(limit, offset, "-1")
+}
+
+func (SQLiteHelper) Cond(cond, then, els string) string {
+	return caseWhen(cond, then, els)
+}
+
+func boolNumber(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
+
+// 标准 SQL 的条件表达式
+func caseWhen(cond, then, els string) string {
+	return "CASE WHEN " + cond + " THEN " + then + " ELSE " + els + " END"
+}
+
+// OFFSET ... FETCH NEXT ... 形式的分页
+func offsetFetch(limit, offset int) string {
+	s := "OFFSET " + strconv.Itoa(max(offset, 0)) + " ROWS"
+	if limit > 0 {
+		s += " FETCH NEXT " + strconv.Itoa(limit) + " ROWS ONLY"
+	}
+	return s
+}
+
+// LIMIT ... OFFSET ... 形式的分页，all 为不限制条数时的写法
+func limitOffset(limit, offset int, all string) string {
+	s := "LIMIT " + all
+	if limit > 0 {
+		s = "LIMIT " + strconv.Itoa(limit)
+	}
+	if offset > 0 {
+		s += " OFFSET " + strconv.Itoa(offset)
+	}
+	return s
+}
+
+// 以 sep 开头拼接各项
+func prefixJoin(sep string, parts []string) string {
+	var b strings.Builder
+	for _, v := range parts {
+		b.WriteString(sep)
+		b.WriteString(v)
+	}
+	return b.String()
+}
@@ -0,0 +1,32 @@
+package dbbase_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/livexy/plugins/dbbase"
+)
+
+// 超出范围的单位写入 Unit(n)，不会 panic
+func TestHelperInvalidUnit(t *testing.T) {
+	if s := dbbase.Unit(9).String(); s != "Unit(9)" {
+		t.Fatalf("String = %q", s)
+	}
+	helpers := map[string]dbbase.Helper{
+		"mysql":     dbbase.MySQLHelper{},
+		"postgres":  dbbase.PostgresHelper{},
+		"oracle":    dbbase.OracleHelper{},
+		"dameng":    dbbase.DamengHelper{},
+		"sqlserver": dbbase.SQLServerHelper{},
+		"sqlite":    dbbase.SQLiteHelper{},
+	}
+	for name, h := range helpers {
+		for _, u := range []dbbase.Unit{-1, 9} {
+			for _, sql := range []string{h.DateTrunc(u, "t"), h.DateAdd("t", 1, u), h.DateDiff(u, "a", "b")} {
+				if !strings.Contains(sql, u.String()) {
+					t.Errorf("%s %s: %s", name, u, sql)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package dbbase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Query 单条语句的执行情况
+type Query struct {
+	Table     string        // 表名，原生 SQL 可能为空
+	Operation string        // create、query、update、delete、row、raw
+	Pool      string        // 执行语句的连接，见 PoolStats 的键；事务中为开启事务的连接
+	Duration  time.Duration // 耗时
+	Rows      int64         // 影响或返回的行数
+	Err       error         // 不含记录不存在
+}
+
+// Metrics 指标接口，由调用方对接 Prometheus 等系统，耗时直方图等由实现按 Table、Operation 聚合
+// 方法在语句执行路径上同步调用，实现需并发安全且不能阻塞
+type Metrics interface {
+	// 每条语句执行结束时调用
+	ObserveQuery(q Query)
+	// 定期上报的连接池统计，name 同 PoolStats 的键
+	ObservePool(name string, stats sql.DBStats)
+}
+
+// Tracer 可选的语句级追踪，Start 返回的 end 在语句结束时调用，name 形如 gorm.query
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, func(err error))
+}
+
+type observer struct {
+	metrics Metrics
+	tracer  Tracer
+}
+
+// 指标状态
+type monitor struct {
+	observer atomic.Pointer[observer] // 未设置时为 nil
+	slow     time.Duration            // 慢查询阈值，0 不记录
+	names    map[gorm.ConnPool]string // 连接名，初始化后只读
+	mu       sync.Mutex
+	stop     chan struct{} // 停止连接池统计上报
+}
+
+// 语句开始时记录的状态
+type queryStart struct {
+	start time.Time
+	end   func(error)
+}
+
+const metricsKey = "dbbase:metrics"
+
+// UseMetrics 设置指标与追踪，interval 大于 0 时按该间隔上报连接池统计
+// 可重复调用替换，metrics、tracer 均为 nil 时关闭
+func (p *DB) UseMetrics(metrics Metrics, tracer Tracer, interval time.Duration) {
+	m := &p.monitor
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+	if metrics == nil && tracer == nil {
+		m.observer.Store(nil)
+		return
+	}
+	m.observer.Store(&observer{metrics: metrics, tracer: tracer})
+	if metrics != nil && interval > 0 {
+		m.stop = make(chan struct{})
+		go p.reportPool(metrics, interval, m.stop)
+	}
+}
+
+// PoolStats 各连接池统计
+// 键为 primary（主库），其余为 组名.source.序号、组名.replica.序号 与健康检查回退主库 组名.fallback，默认组为 default
+func (p *DB) PoolStats() map[string]sql.DBStats {
+	stats := make(map[string]sql.DBStats, len(p.pools))
+	for _, pool := range p.pools {
+		if db, ok := pool.(*sql.DB); ok {
+			stats[p.monitor.names[pool]] = db.Stats()
+		}
+	}
+	return stats
+}
+
+// 定期上报连接池统计
+func (p *DB) reportPool(metrics Metrics, interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			for name, stats := range p.PoolStats() {
+				metrics.ObservePool(name, stats)
+			}
+		}
+	}
+}
+
+// 停止上报
+func (p *DB) stopMetrics() {
+	m := &p.monitor
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+}
+
+// 注册指标回调，开始回调在 dbresolver 选择连接之前，结束回调在全部回调之后
+// 未设置指标且未开启慢查询日志时直接跳过
+func (p *DB) registerMetrics(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("*").Register("dbbase:metrics_start", p.startQuery("create")),
+		cb.Create().After("*").Register("dbbase:metrics_end", p.endQuery("create")),
+		cb.Query().Before("*").Register("dbbase:metrics_start", p.startQuery("query")),
+		cb.Query().After("*").Register("dbbase:metrics_end", p.endQuery("query")),
+		cb.Update().Before("*").Register("dbbase:metrics_start", p.startQuery("update")),
+		cb.Update().After("*").Register("dbbase:metrics_end", p.endQuery("update")),
+		cb.Delete().Before("*").Register("dbbase:metrics_start", p.startQuery("delete")),
+		cb.Delete().After("*").Register("dbbase:metrics_end", p.endQuery("delete")),
+		cb.Row().Before("*").Register("dbbase:metrics_start", p.startQuery("row")),
+		cb.Row().After("*").Register("dbbase:metrics_end", p.endQuery("row")),
+		cb.Raw().Before("*").Register("dbbase:metrics_start", p.startQuery("raw")),
+		cb.Raw().After("*").Register("dbbase:metrics_end", p.endQuery("raw")),
+	)
+}
+
+func (p *DB) startQuery(op string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		obs := p.monitor.observer.Load()
+		if obs == nil && p.monitor.slow <= 0 {
+			return
+		}
+		qs := &queryStart{start: time.Now()}
+		if obs != nil && obs.tracer != nil {
+			db.Statement.Context, qs.end = obs.tracer.Start(db.Statement.Context, "gorm."+op)
+		}
+		db.InstanceSet(metricsKey, qs)
+	}
+}
+
+func (p *DB) endQuery(op string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(metricsKey)
+		if !ok {
+			return
+		}
+		qs := v.(*queryStart)
+		elapsed := time.Since(qs.start)
+		err := db.Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil
+		}
+		pool := p.poolName(db.Statement.ConnPool)
+		if obs := p.monitor.observer.Load(); obs != nil && obs.metrics != nil {
+			obs.metrics.ObserveQuery(Query{
+				Table: db.Statement.Table, Operation: op, Pool: pool,
+				Duration: elapsed, Rows: db.RowsAffected, Err: err,
+			})
+		}
+		if qs.end != nil {
+			qs.end(err)
+		}
+		if slow := p.monitor.slow; slow > 0 && elapsed > slow && db.Statement.SQL.Len() > 0 {
+			sql := db.Dialector.Explain(db.Statement.SQL.String(), db.Statement.Vars...)
+			db.Logger.Warn(db.Statement.Context, "慢查询 %s > %s [%s] [rows:%d] %s", elapsed, slow, pool, db.RowsAffected, sql)
+		}
+	}
+}
+
+// 按连接取名，开启预编译时连接包装为 PreparedStmtDB，事务包装为 PreparedStmtTX 并保留开启事务的连接
+func (p *DB) poolName(pool gorm.ConnPool) string {
+	for {
+		switch v := pool.(type) {
+		case *gorm.PreparedStmtTX:
+			pool = v.PreparedStmtDB
+		case *gorm.PreparedStmtDB:
+			pool = v.ConnPool
+		case gorm.TxCommitter:
+			// 未经预编译包装的事务无法取得开启事务的连接
+			return "tx"
+		default:
+			return p.monitor.names[pool]
+		}
+	}
+}
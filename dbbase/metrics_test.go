@@ -0,0 +1,155 @@
+package dbbase_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/livexy/plugin/dber"
+	"github.com/livexy/plugins/dbbase"
+	sqlite "github.com/livexy/plugins/sqlite/plugin"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 记录语句指标
+type recordMetrics struct {
+	mu      sync.Mutex
+	queries []dbbase.Query
+}
+
+func (m *recordMetrics) ObserveQuery(q dbbase.Query) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queries = append(m.queries, q)
+}
+
+func (m *recordMetrics) ObservePool(string, sql.DBStats) {}
+
+// 取出并清空已记录的语句，返回 表.操作@连接 列表
+func (m *recordMetrics) take() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := make([]string, 0, len(m.queries))
+	for _, q := range m.queries {
+		list = append(list, q.Table+"."+q.Operation+"@"+q.Pool)
+	}
+	m.queries = nil
+	return list
+}
+
+func TestMetricsPool(t *testing.T) {
+	dir := t.TempDir()
+	db := sqlite.New().(*dbbase.DB)
+	_, err := db.InitWithConfig("test", dbbase.Config{
+		DBConfig: dber.DBConfig{Sources: []string{seedDB(t, dir, "primary"), seedDB(t, dir, "source")}, Replicas: []string{seedDB(t, dir, "replica")}},
+		Audit:    true,
+		AuditSink: dbbase.AuditSinkFunc(func(*gorm.DB, []dbbase.Change) error {
+			return nil
+		}),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	m := &recordMetrics{}
+	db.UseMetrics(m, nil, 0)
+	gdb := db.DB()
+
+	var names []string
+	if err = gdb.Table("who").Pluck("name", &names).Error; err != nil {
+		t.Fatal(err)
+	}
+	// 审计为写入开启事务，事务中的语句仍标记开启事务的连接
+	if err = gdb.Table("log").Create(map[string]any{"name": "x"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	err = gdb.Transaction(func(tx *gorm.DB) error {
+		return tx.Table("log").Where("name = ?", "x").Update("name", "y").Error
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 更新前审计在事务中读取旧值
+	want := []string{"who.query@default.replica.0", "log.create@default.source.0", "log.query@primary", "log.update@primary"}
+	if got := m.take(); !slices.Equal(got, want) {
+		t.Fatalf("queries = %v, want %v", got, want)
+	}
+
+	db.UseMetrics(nil, nil, 0)
+	if err = gdb.Table("who").Pluck("name", &names).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := m.take(); len(got) != 0 {
+		t.Fatalf("queries after disable = %v", got)
+	}
+}
+
+// 记录 Warn 日志
+type warnLogger struct {
+	logger.Interface
+	warns []string
+}
+
+func (l *warnLogger) Warn(_ context.Context, msg string, args ...any) {
+	l.warns = append(l.warns, fmt.Sprintf(msg, args...))
+}
+
+func TestMetricsSlow(t *testing.T) {
+	dir := t.TempDir()
+	for _, slow := range []time.Duration{0, time.Nanosecond} {
+		db := sqlite.New().(*dbbase.DB)
+		_, err := db.InitWithConfig("test", dbbase.Config{
+			DBConfig:      dber.DBConfig{Sources: []string{seedDB(t, dir, fmt.Sprint("slow", slow))}},
+			SlowThreshold: slow,
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l := &warnLogger{Interface: logger.Discard}
+		var names []string
+		err = db.DB().Session(&gorm.Session{Logger: l}).Table("who").Where("name <> ?", "x").Pluck("name", &names).Error
+		_ = db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if slow == 0 {
+			if len(l.warns) != 0 {
+				t.Fatalf("warns = %v", l.warns)
+			}
+			continue
+		}
+		if len(l.warns) != 1 || !strings.Contains(l.warns[0], "慢查询") || !strings.Contains(l.warns[0], "[primary]") ||
+			!strings.Contains(l.warns[0], `name <> "x"`) {
+			t.Fatalf("warns = %v", l.warns)
+		}
+	}
+}
+
+func TestPoolStats(t *testing.T) {
+	dsn := seedDB(t, t.TempDir(), "primary")
+	db := sqlite.New().(*dbbase.DB)
+	_, err := db.InitWithConfig("test", dbbase.Config{
+		DBConfig:    dber.DBConfig{Sources: []string{dsn, dsn}, Replicas: []string{dsn, dsn}},
+		HealthCheck: time.Hour,
+		Routes:      []dbbase.Route{{Name: "logs", Tables: []string{"log"}, Replicas: []string{dsn}}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	want := []string{
+		"default.fallback", "default.replica.0", "default.replica.1", "default.source.0",
+		"logs.fallback", "logs.replica.0", "primary",
+	}
+	if got := slices.Sorted(maps.Keys(db.PoolStats())); !slices.Equal(got, want) {
+		t.Fatalf("keys = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package dbbase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type fakePool struct {
+	gorm.ConnPool
+	err error
+}
+
+func (f *fakePool) PingContext(context.Context) error {
+	return f.err
+}
+
+// 两个副本加回退主库
+func newTestPolicy(kind string, weights []int) (*DB, *replicaPolicy, []*fakePool, []gorm.ConnPool) {
+	p := &DB{}
+	rp := newReplicaPolicy(kind, weights)
+	fakes := []*fakePool{{}, {}, {}}
+	for i, pool := range fakes[:2] {
+		r := &replica{group: "default", index: i, pool: pool}
+		r.healthy.Store(true)
+		rp.replicas[pool] = r
+		p.replicas = append(p.replicas, r)
+	}
+	rp.fallback = true
+	return p, rp, fakes, []gorm.ConnPool{fakes[0], fakes[1], fakes[2]}
+}
+
+func TestReplicaPolicyHealth(t *testing.T) {
+	p, rp, fakes, pools := newTestPolicy(PolicyRoundRobin, nil)
+	seen := map[gorm.ConnPool]int{}
+	for range 10 {
+		seen[rp.Resolve(pools)]++
+	}
+	if seen[fakes[0]] != 5 || seen[fakes[1]] != 5 {
+		t.Fatalf("seen = %v", seen)
+	}
+
+	// 连续失败 failThreshold 次后剔除
+	fakes[0].err = errors.New("down")
+	p.checkReplica(p.replicas[0], time.Second)
+	if !p.ReplicaStats()[0].Healthy {
+		t.Fatal("ejected after one failure")
+	}
+	p.checkReplica(p.replicas[0], time.Second)
+	for range 5 {
+		if pool := rp.Resolve(pools); pool != fakes[1] {
+			t.Fatalf("resolved %p", pool)
+		}
+	}
+
+	// 副本全部不可用时回退主库
+	fakes[1].err = errors.New("down")
+	for range failThreshold {
+		p.checkReplica(p.replicas[1], time.Second)
+	}
+	if pool := rp.Resolve(pools); pool != fakes[2] {
+		t.Fatalf("resolved %p", pool)
+	}
+
+	// 恢复后重新加入
+	fakes[0].err = nil
+	p.checkReplica(p.replicas[0], time.Second)
+	if pool := rp.Resolve(pools); pool != fakes[0] {
+		t.Fatalf("resolved %p", pool)
+	}
+	stats := p.ReplicaStats()
+	if !stats[0].Healthy || stats[0].Failures != 0 || stats[0].Errors != 2 || stats[1].Healthy || stats[1].LastError != "down" {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestReplicaPolicyWeights(t *testing.T) {
+	_, rp, fakes, pools := newTestPolicy(PolicyWeighted, []int{0, 1})
+	for range 20 {
+		if pool := rp.Resolve(pools); pool != fakes[1] {
+			t.Fatalf("resolved %p", pool)
+		}
+	}
+
+	// 主库列表不在副本中，随机选择，不套用副本权重与回退
+	sources := []gorm.ConnPool{&fakePool{}, &fakePool{}, &fakePool{}}
+	seen := map[gorm.ConnPool]int{}
+	for range 300 {
+		seen[rp.Resolve(sources)]++
+	}
+	if len(seen) != 3 {
+		t.Fatalf("seen %d sources", len(seen))
+	}
+}
@@ -0,0 +1,321 @@
+package dbbase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// 读写分离策略
+const (
+	PolicyRandom     = "random"     // 随机，默认
+	PolicyRoundRobin = "roundrobin" // 轮询
+	PolicyWeighted   = "weighted"   // 按 Weights 加权随机
+	PolicyLatency    = "latency"    // 选择健康检查延迟最低的副本，需开启健康检查
+)
+
+// 校验读写分离策略，为空时使用随机
+func checkPolicy(policy string, health bool) error {
+	switch policy {
+	case "", PolicyRandom, PolicyRoundRobin, PolicyWeighted:
+		return nil
+	case PolicyLatency:
+		if !health {
+			return errors.New("读写分离策略 latency 需要配置 healthCheck")
+		}
+		return nil
+	}
+	return fmt.Errorf("未知的读写分离策略：%s", policy)
+}
+
+// Route 将指定表或模型绑定到单独的主库与副本组
+type Route struct {
+	Name     string   `yaml:"name"`     // 名称，用于统计
+	Tables   []string `yaml:"tables"`   // 表名
+	Models   []any    `yaml:"-"`        // 模型，代码配置时使用
+	Sources  []string `yaml:"sources"`  // 主库，为空时使用默认主库
+	Replicas []string `yaml:"replicas"` // 副本，为空时读写均走主库
+	Policy   string   `yaml:"policy"`
+	Weights  []int    `yaml:"weights"`
+}
+
+// ReplicaStat 副本健康统计
+type ReplicaStat struct {
+	Group     string        // 默认组为 default，其余为 Route 名称
+	Index     int           // 在 Replicas 中的序号
+	Healthy   bool          // 当前是否参与读取
+	Failures  int64         // 连续失败次数
+	Checks    int64         // 累计检查次数
+	Errors    int64         // 累计失败次数
+	Latency   time.Duration // 最近检查延迟的滑动平均
+	LastError string
+	LastCheck time.Time
+}
+
+// 副本状态
+type replica struct {
+	group string
+	index int
+	pool  gorm.ConnPool // 由 trackDialector 在初始化时写入
+
+	healthy  atomic.Bool
+	latency  atomic.Int64
+	failures atomic.Int64
+	checks   atomic.Int64
+	errors   atomic.Int64
+
+	mu        sync.Mutex
+	lastError string
+	lastCheck time.Time
+}
+
+func (r *replica) stat() ReplicaStat {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return ReplicaStat{
+		Group: r.group, Index: r.index, Healthy: r.healthy.Load(),
+		Failures: r.failures.Load(), Checks: r.checks.Load(), Errors: r.errors.Load(),
+		Latency: time.Duration(r.latency.Load()), LastError: r.lastError, LastCheck: r.lastCheck,
+	}
+}
+
+// 记录连接池的方言包装，dbresolver 打开连接时经由 Initialize 取得连接池
+type trackDialector struct {
+	gorm.Dialector
+	track func(pool gorm.ConnPool)
+}
+
+func (d trackDialector) Initialize(db *gorm.DB) error {
+	err := d.Dialector.Initialize(db)
+	if err == nil {
+		d.track(db.ConnPool)
+	}
+	return err
+}
+
+func (d trackDialector) Apply(config *gorm.Config) error {
+	if v, ok := d.Dialector.(interface{ Apply(*gorm.Config) error }); ok {
+		return v.Apply(config)
+	}
+	return nil
+}
+
+func (d trackDialector) Translate(err error) error {
+	if v, ok := d.Dialector.(gorm.ErrorTranslator); ok {
+		return v.Translate(err)
+	}
+	return err
+}
+
+// 带健康检查的副本选择策略
+// 副本全部不可用时，读取回退到最后一个连接（主库），只有一个副本时同样追加主库以便剔除
+// dbresolver 写入时以同一策略在多个主库中选择，主库随机选择，不参与剔除与权重
+type replicaPolicy struct {
+	kind     string
+	weights  []int
+	counter  atomic.Uint64
+	replicas map[gorm.ConnPool]*replica
+	fallback bool // 最后一个连接为回退主库
+}
+
+func newReplicaPolicy(kind string, weights []int) *replicaPolicy {
+	return &replicaPolicy{kind: kind, weights: weights, replicas: map[gorm.ConnPool]*replica{}}
+}
+
+func (p *replicaPolicy) Resolve(pools []gorm.ConnPool) gorm.ConnPool {
+	n := len(pools)
+	if _, ok := p.replicas[pools[0]]; !ok {
+		return pools[rand.N(n)]
+	}
+	candidates := make([]int, 0, n)
+	for i, pool := range pools {
+		r, ok := p.replicas[pool]
+		if ok && r.healthy.Load() {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		// 副本全部不可用
+		if p.fallback {
+			return pools[n-1]
+		}
+		for i := range n {
+			candidates = append(candidates, i)
+		}
+	}
+	switch p.kind {
+	case PolicyRoundRobin:
+		return pools[candidates[int(p.counter.Add(1)%uint64(len(candidates)))]]
+	case PolicyWeighted:
+		total := 0
+		for _, i := range candidates {
+			total += p.weight(i)
+		}
+		if total > 0 {
+			x := rand.N(total)
+			for _, i := range candidates {
+				x -= p.weight(i)
+				if x < 0 {
+					return pools[i]
+				}
+			}
+		}
+	case PolicyLatency:
+		best, bestLatency := -1, int64(0)
+		for _, i := range candidates {
+			r, ok := p.replicas[pools[i]]
+			if !ok || r.latency.Load() == 0 {
+				continue
+			}
+			if best < 0 || r.latency.Load() < bestLatency {
+				best, bestLatency = i, r.latency.Load()
+			}
+		}
+		if best >= 0 {
+			return pools[best]
+		}
+	}
+	return pools[candidates[rand.N(len(candidates))]]
+}
+
+// 未配置权重的副本权重为 1
+func (p *replicaPolicy) weight(i int) int {
+	if i < len(p.weights) {
+		return max(p.weights[i], 0)
+	}
+	return 1
+}
+
+// 构造一组读写分离配置，连接均以 trackDialector 包装，以便健康检查与关闭
+func (p *DB) resolverConfig(group, policy string, weights []int, sources, replicas []string, health bool) dbresolver.Config {
+	rp := newReplicaPolicy(policy, weights)
+	conf := dbresolver.Config{Policy: rp}
+	for i, v := range sources {
+		conf.Sources = append(conf.Sources, p.track(fmt.Sprintf("%s.source.%d", group, i), v, nil))
+	}
+	for i, v := range replicas {
+		r := &replica{group: group, index: i}
+		r.healthy.Store(true)
+		p.replicas = append(p.replicas, r)
+		conf.Replicas = append(conf.Replicas, p.track(fmt.Sprintf("%s.replica.%d", group, i), v, func(pool gorm.ConnPool) {
+			r.pool = pool
+			rp.replicas[pool] = r
+		}))
+	}
+	if health && len(replicas) > 0 {
+		// 追加主库作为回退连接，副本全部剔除后读取主库
+		primary := p.primaryDSN
+		if len(sources) > 0 {
+			primary = sources[0]
+		}
+		conf.Replicas = append(conf.Replicas, p.track(group+".fallback", primary, nil))
+		rp.fallback = true
+	}
+	return conf
+}
+
+func (p *DB) track(name, dsn string, fn func(pool gorm.ConnPool)) gorm.Dialector {
+	return trackDialector{
+		Dialector: p.dialect.Open(dsn),
+		track: func(pool gorm.ConnPool) {
+			p.pools = append(p.pools, pool)
+			p.monitor.names[pool] = name
+			if fn != nil {
+				fn(pool)
+			}
+		},
+	}
+}
+
+// ReplicaStats 副本健康统计
+func (p *DB) ReplicaStats() []ReplicaStat {
+	stats := make([]ReplicaStat, 0, len(p.replicas))
+	for _, r := range p.replicas {
+		stats = append(stats, r.stat())
+	}
+	return stats
+}
+
+// 定期检查副本，连续失败 failThreshold 次后剔除，恢复后重新加入
+const failThreshold = 2
+
+func (p *DB) healthCheck(interval, timeout time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			for _, r := range p.replicas {
+				p.checkReplica(r, timeout)
+			}
+		}
+	}
+}
+
+func (p *DB) checkReplica(r *replica, timeout time.Duration) {
+	if r.pool == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := time.Now()
+	err := ping(ctx, r.pool)
+	elapsed := time.Since(start)
+	r.checks.Add(1)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastCheck = start
+	if err != nil {
+		r.errors.Add(1)
+		r.lastError = err.Error()
+		if r.failures.Add(1) >= failThreshold {
+			r.healthy.Store(false)
+		}
+		return
+	}
+	r.lastError = ""
+	r.failures.Store(0)
+	r.healthy.Store(true)
+	// 滑动平均，新值占 1/4
+	old := r.latency.Load()
+	if old == 0 {
+		r.latency.Store(int64(elapsed))
+	} else {
+		r.latency.Store((old*3 + int64(elapsed)) / 4)
+	}
+}
+
+func ping(ctx context.Context, pool gorm.ConnPool) error {
+	if db, ok := pool.(interface{ PingContext(context.Context) error }); ok {
+		return db.PingContext(ctx)
+	}
+	var n int
+	return pool.QueryRowContext(ctx, "SELECT 1").Scan(&n)
+}
+
+// Close 停止健康检查与指标上报并关闭全部连接
+func (p *DB) Close() error {
+	p.stopMetrics()
+	if p.stop != nil {
+		close(p.stop)
+		p.stop = nil
+	}
+	var err error
+	for _, pool := range p.pools {
+		if db, ok := pool.(*sql.DB); ok {
+			err = errors.Join(err, db.Close())
+		}
+	}
+	p.pools = nil
+	return err
+}
@@ -0,0 +1,166 @@
+package dbbase_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/livexy/plugin/dber"
+	"github.com/livexy/plugins/dbbase"
+	sqlite "github.com/livexy/plugins/sqlite/plugin"
+)
+
+func TestResolverPolicy(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "test.db")
+	tests := []struct {
+		name string
+		cfg  dbbase.Config
+		err  string
+	}{
+		{"default", dbbase.Config{}, ""},
+		{"roundrobin", dbbase.Config{Policy: dbbase.PolicyRoundRobin}, ""},
+		{"unknown", dbbase.Config{Policy: "roundrobbin"}, "roundrobbin"},
+		{"latency without health", dbbase.Config{Policy: dbbase.PolicyLatency}, "healthCheck"},
+		{"latency", dbbase.Config{Policy: dbbase.PolicyLatency, HealthCheck: time.Minute}, ""},
+		{"route", dbbase.Config{Routes: []dbbase.Route{{Tables: []string{"user"}, Policy: "fastest"}}}, "route user"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.DBConfig = dber.DBConfig{Sources: []string{dsn}, Replicas: []string{dsn}}
+			db := sqlite.New().(*dbbase.DB)
+			_, err := db.InitWithConfig("test", tt.cfg, nil)
+			if err == nil {
+				_ = db.Close()
+			}
+			switch {
+			case len(tt.err) == 0 && err != nil:
+				t.Fatal(err)
+			case len(tt.err) > 0 && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+// 创建 sqlite 库，who 与 log 表各写入一行库名，用于判断语句落在哪个连接
+func seedDB(t *testing.T, dir, name string) string {
+	t.Helper()
+	dsn := filepath.Join(dir, name+".db")
+	db := sqlite.New().(*dbbase.DB)
+	_, err := db.InitWithConfig("test", dbbase.Config{DBConfig: dber.DBConfig{Sources: []string{dsn}}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	for _, table := range []string{"who", "log"} {
+		err = db.DB().Exec("create table " + table + " (name text)").Error
+		if err == nil {
+			err = db.DB().Table(table).Create(map[string]any{"name": name}).Error
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dsn
+}
+
+// 表中各库名的行数
+func countRows(t *testing.T, dsn, table, name string) int64 {
+	t.Helper()
+	db := sqlite.New().(*dbbase.DB)
+	_, err := db.InitWithConfig("test", dbbase.Config{DBConfig: dber.DBConfig{Sources: []string{dsn}}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	var n int64
+	if err = db.DB().Table(table).Where("name = ?", name).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// 多个主库时写入在主库间分布，副本权重与回退主库不作用于写入
+func TestResolverSources(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{"p", "s1", "s2", "s3", "r1"}
+	dsns := map[string]string{}
+	for _, name := range names {
+		dsns[name] = seedDB(t, dir, name)
+	}
+	db := sqlite.New().(*dbbase.DB)
+	_, err := db.InitWithConfig("test", dbbase.Config{
+		DBConfig: dber.DBConfig{
+			Sources:  []string{dsns["p"], dsns["s1"], dsns["s2"], dsns["s3"]},
+			Replicas: []string{dsns["r1"]},
+		},
+		Policy: dbbase.PolicyWeighted, Weights: []int{1, 0, 0}, HealthCheck: time.Hour,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 60 {
+		if err = db.DB().Table("who").Create(map[string]any{"name": "w"}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	var who []string
+	if err = db.DB().Table("who").Where("name <> ?", "w").Pluck("name", &who).Error; err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+	if len(who) != 1 || who[0] != "r1" {
+		t.Fatalf("read from %v", who)
+	}
+	for _, name := range []string{"s1", "s2", "s3"} {
+		if n := countRows(t, dsns[name], "who", "w"); n == 0 {
+			t.Fatalf("source %s got no writes", name)
+		}
+	}
+	for _, name := range []string{"p", "r1"} {
+		if n := countRows(t, dsns[name], "who", "w"); n != 0 {
+			t.Fatalf("%s got %d writes", name, n)
+		}
+	}
+}
+
+// Route 绑定的表使用独立的主库与副本
+func TestResolverRoutes(t *testing.T) {
+	dir := t.TempDir()
+	dsns := map[string]string{}
+	for _, name := range []string{"p", "r1", "l1", "lr1"} {
+		dsns[name] = seedDB(t, dir, name)
+	}
+	db := sqlite.New().(*dbbase.DB)
+	_, err := db.InitWithConfig("test", dbbase.Config{
+		DBConfig: dber.DBConfig{Sources: []string{dsns["p"]}, Replicas: []string{dsns["r1"]}},
+		Routes: []dbbase.Route{{
+			Name: "log", Tables: []string{"log"}, Sources: []string{dsns["l1"]}, Replicas: []string{dsns["lr1"]},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var who, log []string
+	err = db.DB().Table("who").Pluck("name", &who).Error
+	if err == nil {
+		err = db.DB().Table("log").Pluck("name", &log).Error
+	}
+	if err == nil {
+		err = db.DB().Table("who").Create(map[string]any{"name": "w"}).Error
+	}
+	if err == nil {
+		err = db.DB().Table("log").Create(map[string]any{"name": "w"}).Error
+	}
+	_ = db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(who) != 1 || who[0] != "r1" || len(log) != 1 || log[0] != "lr1" {
+		t.Fatalf("who = %v, log = %v", who, log)
+	}
+	if countRows(t, dsns["p"], "who", "w") != 1 || countRows(t, dsns["l1"], "log", "w") != 1 {
+		t.Fatal("writes not routed")
+	}
+}
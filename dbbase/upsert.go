@@ -0,0 +1,313 @@
+package dbbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+)
+
+// UpsertMode 插入冲突时更新的语法
+type UpsertMode int
+
+const (
+	// UpsertOnDuplicateKey INSERT ... ON DUPLICATE KEY UPDATE，按表的全部唯一索引判断冲突
+	UpsertOnDuplicateKey UpsertMode = iota
+	// UpsertOnConflict INSERT ... ON CONFLICT (...) DO UPDATE
+	UpsertOnConflict
+	// UpsertMerge MERGE INTO ... USING (SELECT ...) excluded ON (...)
+	UpsertMerge
+)
+
+// ErrNoConflict 未指定冲突列且模型没有主键
+var ErrNoConflict = errors.New("upsert 需要指定冲突列")
+
+// Upsert 插入或更新选项
+// Updates 与 Increments 都为空且未设置 DoNothing 时，冲突后以新值覆盖冲突列以外的全部插入列
+type Upsert struct {
+	Table      string         // 表名，values 为 map 时必填
+	Columns    []string       // 冲突列，为空时使用主键；UpsertOnDuplicateKey 方式仅在审计时用于读取旧值
+	Updates    []string       // 冲突时以新值覆盖的列
+	Increments map[string]any // 冲突时在原值上累加的列，与 ExAdd 不同，始终基于表中已有的值
+	DoNothing  bool           // 冲突时保留原记录
+	BatchSize  int            // 每条语句的行数，为 0 时按 Slots 计算，占位符总数不超过 Slots
+}
+
+// Upserter 插入或更新
+type Upserter interface {
+	Upsert(ctx context.Context, values any, opt Upsert) (int64, error)
+}
+
+// Upsert 插入记录，按冲突列与已有记录冲突时更新，返回影响行数，多批或开启审计时在事务中执行
+// values 为模型指针、模型切片、map 或 map 切片，不触发模型的钩子
+// 开启审计时按冲突列读取前后的行并记录插入与实际变更的列
+func (p *DB) Upsert(ctx context.Context, values any, opt Upsert) (int64, error) {
+	if p.db == nil {
+		return 0, ErrNotInit
+	}
+	tx := p.db.WithContext(ctx)
+	if len(opt.Table) > 0 {
+		tx = tx.Table(opt.Table)
+	}
+	stmt := tx.Statement
+	stmt.Dest = values
+	stmt.Model = values
+	stmt.ReflectValue = reflect.Indirect(reflect.ValueOf(values))
+	if !isMap(values) && !isMapSlice(values) {
+		if err := stmt.Parse(values); err != nil {
+			return 0, err
+		}
+	}
+	if len(stmt.Table) == 0 {
+		return 0, gorm.ErrModelValueRequired
+	}
+	vals := callbacks.ConvertToCreateValues(stmt)
+	if stmt.Error != nil {
+		return 0, stmt.Error
+	}
+	if len(vals.Values) == 0 {
+		return 0, nil
+	}
+	u := p.upsertPlan(stmt, vals.Columns, opt)
+	if p.dialect.Upsert != UpsertOnDuplicateKey && len(u.conflict) == 0 {
+		return 0, ErrNoConflict
+	}
+	// MERGE 以插入列组成源表，缺少冲突列（如值为零的自增主键）时 ON 条件无法引用
+	if p.dialect.Upsert == UpsertMerge {
+		for _, c := range u.conflict {
+			if !slices.ContainsFunc(vals.Columns, func(col clause.Column) bool { return col.Name == c }) {
+				return 0, fmt.Errorf("%w：插入列中没有冲突列 %s，自增主键为零时请指定其他冲突列", ErrNoConflict, c)
+			}
+		}
+	}
+	size := opt.BatchSize
+	if size <= 0 {
+		// 每行占用 len(vals.Columns) 个占位符，累加值每条语句占用一个
+		size = max((p.dialect.Slots-len(u.incs))/max(len(vals.Columns), 1), 1)
+	}
+	exec := func(tx *gorm.DB, rows [][]any) (int64, error) {
+		sql, vars := p.upsertSQL(u, rows)
+		res := tx.Exec(sql, vars...)
+		return res.RowsAffected, res.Error
+	}
+	audit := p.audit != nil && p.audit.audited(stmt.Table)
+	if audit {
+		exec = p.audit.upsert(stmt, u, exec)
+	}
+	if len(vals.Values) <= size && !audit {
+		return exec(p.db.WithContext(ctx), vals.Values)
+	}
+	var affected int64
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for rows := range slices.Chunk(vals.Values, size) {
+			n, err := exec(tx, rows)
+			if err != nil {
+				return err
+			}
+			affected += n
+		}
+		return nil
+	})
+	return affected, err
+}
+
+type increment struct {
+	column string
+	value  any
+}
+
+// 生成语句所需的列信息
+type upsertPlan struct {
+	table     string
+	columns   []clause.Column
+	conflict  []string
+	updates   []string
+	incs      []increment
+	doNothing bool
+}
+
+func (p *DB) upsertPlan(stmt *gorm.Statement, columns []clause.Column, opt Upsert) upsertPlan {
+	u := upsertPlan{table: stmt.Table, columns: columns, conflict: opt.Columns, doNothing: opt.DoNothing}
+	if len(u.conflict) == 0 && stmt.Schema != nil {
+		for _, field := range stmt.Schema.PrimaryFields {
+			u.conflict = append(u.conflict, field.DBName)
+		}
+	}
+	if u.doNothing {
+		return u
+	}
+	updates := opt.Updates
+	if len(updates) == 0 && len(opt.Increments) == 0 {
+		for _, c := range columns {
+			if stmt.Schema != nil {
+				if field := stmt.Schema.LookUpField(c.Name); field != nil && (field.PrimaryKey || field.AutoCreateTime > 0) {
+					continue
+				}
+			}
+			updates = append(updates, c.Name)
+		}
+	}
+	// 冲突列不能出现在 MERGE 的更新中，其余方式更新也无意义
+	for _, c := range updates {
+		if !slices.Contains(u.conflict, c) {
+			u.updates = append(u.updates, c)
+		}
+	}
+	for _, c := range slices.Sorted(maps.Keys(opt.Increments)) {
+		u.incs = append(u.incs, increment{column: c, value: opt.Increments[c]})
+	}
+	u.doNothing = len(u.updates) == 0 && len(u.incs) == 0
+	return u
+}
+
+// 按方言生成语句，列名与表名以 clause.Column、clause.Table 作为参数由 gorm 加引号
+func (p *DB) upsertSQL(u upsertPlan, rows [][]any) (string, []any) {
+	var b strings.Builder
+	var vars []any
+	table := clause.Table{Name: u.table}
+	target := func(c string) clause.Column {
+		return clause.Column{Table: u.table, Name: c}
+	}
+	// ON DUPLICATE KEY UPDATE 中的列名即为已有的值
+	current := target
+	if p.dialect.Upsert == UpsertOnDuplicateKey {
+		current = func(c string) clause.Column {
+			return clause.Column{Name: c}
+		}
+	}
+	excluded := func(c string) clause.Column {
+		return clause.Column{Table: "excluded", Name: c}
+	}
+	// 累加与覆盖的赋值，newValue 为新值的写法
+	assignments := func(newValue func(c string) (string, []any)) {
+		for i, c := range u.updates {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			sql, v := newValue(c)
+			b.WriteString("? = " + sql)
+			vars = append(vars, clause.Column{Name: c})
+			vars = append(vars, v...)
+		}
+		for i, inc := range u.incs {
+			if i > 0 || len(u.updates) > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString("? = ? + ?")
+			vars = append(vars, clause.Column{Name: inc.column}, current(inc.column), inc.value)
+		}
+	}
+	if p.dialect.Upsert == UpsertMerge {
+		b.WriteString("MERGE INTO ? USING (")
+		vars = append(vars, table)
+		for i, row := range rows {
+			if i > 0 {
+				b.WriteString(" UNION ALL ")
+			}
+			b.WriteString("SELECT ")
+			for j, v := range row {
+				if j > 0 {
+					b.WriteString(", ")
+				}
+				b.WriteString("? AS ?")
+				vars = append(vars, v, u.columns[j])
+			}
+			if len(p.dialect.DualTable) > 0 {
+				b.WriteString(" FROM " + p.dialect.DualTable)
+			}
+		}
+		b.WriteString(") ? ON (")
+		vars = append(vars, clause.Table{Name: "excluded"})
+		for i, c := range u.conflict {
+			if i > 0 {
+				b.WriteString(" AND ")
+			}
+			b.WriteString("? = ?")
+			vars = append(vars, target(c), excluded(c))
+		}
+		b.WriteString(")")
+		if !u.doNothing {
+			b.WriteString(" WHEN MATCHED THEN UPDATE SET ")
+			assignments(func(c string) (string, []any) {
+				return "?", []any{excluded(c)}
+			})
+		}
+		b.WriteString(" WHEN NOT MATCHED THEN INSERT (")
+		for i, c := range u.columns {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString("?")
+			vars = append(vars, c)
+		}
+		b.WriteString(") VALUES (")
+		for i, c := range u.columns {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString("?")
+			vars = append(vars, excluded(c.Name))
+		}
+		b.WriteString(")" + p.dialect.MergeSuffix)
+		return b.String(), vars
+	}
+	b.WriteString("INSERT INTO ? (")
+	vars = append(vars, table)
+	for i, c := range u.columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("?")
+		vars = append(vars, c)
+	}
+	b.WriteString(") VALUES ")
+	for i, row := range rows {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("?")
+		vars = append(vars, row)
+	}
+	if p.dialect.Upsert == UpsertOnDuplicateKey {
+		b.WriteString(" ON DUPLICATE KEY UPDATE ")
+		if u.doNothing {
+			b.WriteString("? = ?")
+			vars = append(vars, u.columns[0], u.columns[0])
+			return b.String(), vars
+		}
+		assignments(func(c string) (string, []any) {
+			return "VALUES(?)", []any{clause.Column{Name: c}}
+		})
+		return b.String(), vars
+	}
+	b.WriteString(" ON CONFLICT (")
+	for i, c := range u.conflict {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("?")
+		vars = append(vars, clause.Column{Name: c})
+	}
+	if u.doNothing {
+		b.WriteString(") DO NOTHING")
+		return b.String(), vars
+	}
+	b.WriteString(") DO UPDATE SET ")
+	assignments(func(c string) (string, []any) {
+		return "?", []any{excluded(c)}
+	})
+	return b.String(), vars
+}
+
+// 是否为 map 切片
+func isMapSlice(values any) bool {
+	rv := reflect.Indirect(reflect.ValueOf(values))
+	return (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem().Kind() == reflect.Map
+}
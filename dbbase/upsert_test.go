@@ -0,0 +1,72 @@
+package dbbase_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/livexy/plugin/dber"
+	"github.com/livexy/plugins/dbbase"
+	sqlite "github.com/livexy/plugins/sqlite/plugin"
+
+	"gorm.io/gorm"
+)
+
+// 按 Slots 分批时每条语句的占位符数不超过 Slots
+func TestUpsertBatchSlots(t *testing.T) {
+	tests := []struct {
+		slots int
+		incs  map[string]any
+		stmts int
+		most  int
+	}{
+		{6, nil, 3, 6},                      // 每行 3 个，恰好用满
+		{7, nil, 3, 6},                      // 余下 1 个不足一行
+		{7, map[string]any{"qty": 1}, 3, 7}, // 累加值每条语句占 1 个
+		{2, nil, 5, 3},                      // 不足一行时每条语句一行
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("slots%d_incs%d", tt.slots, len(tt.incs)), func(t *testing.T) {
+			dialect := sqlite.New().(*dbbase.DB).Dialect()
+			dialect.Slots = tt.slots
+			db := dbbase.New(dialect)
+			_, err := db.Init("test", dber.DBConfig{Sources: []string{filepath.Join(t.TempDir(), "test.db")}}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = db.Close() }()
+			gdb := db.DB()
+			if err = gdb.Exec("create table stock (id integer primary key, name text, qty integer)").Error; err != nil {
+				t.Fatal(err)
+			}
+			var counts []int
+			err = gdb.Callback().Raw().After("gorm:raw").Register("test:count", func(tx *gorm.DB) {
+				counts = append(counts, strings.Count(tx.Statement.SQL.String(), "?"))
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows := make([]map[string]any, 0, 5)
+			for i := range 5 {
+				rows = append(rows, map[string]any{"id": i + 1, "name": "n", "qty": i})
+			}
+			opt := dbbase.Upsert{Table: "stock", Columns: []string{"id"}, Increments: tt.incs}
+			if _, err = db.Upsert(context.Background(), rows, opt); err != nil {
+				t.Fatal(err)
+			}
+			if len(counts) != tt.stmts {
+				t.Fatalf("statements = %d, want %d", len(counts), tt.stmts)
+			}
+			if most := slices.Max(counts); most != tt.most {
+				t.Fatalf("placeholders = %v, want at most %d", counts, tt.most)
+			}
+			var n int64
+			if err = gdb.Table("stock").Count(&n).Error; err != nil || n != 5 {
+				t.Fatalf("count = %d, %v", n, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package dbbasetest
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// 比对方言对模型增删改查生成的语句
+// 用例：create 自增主键回填，find 条件、排序与分页，update 表达式更新，delete 按主键删除，
+// cond 空值判断与条件表达式
+func crud(t *testing.T, r *Recorder, want Snapshots) {
+	h := r.Helper()
+	tests := []struct {
+		name string
+		run  func(db *gorm.DB) error
+	}{
+		{"create", func(db *gorm.DB) error {
+			return db.Create(&Stock{Name: "a", Qty: 1}).Error
+		}},
+		{"find", func(db *gorm.DB) error {
+			var list []Stock
+			return db.Where("qty > ?", 1).Order("id").Limit(10).Offset(20).Find(&list).Error
+		}},
+		{"update", func(db *gorm.DB) error {
+			return db.Model(&Stock{ID: 1}).Updates(map[string]any{"qty": gorm.Expr("qty + ?", 1), "name": "b"}).Error
+		}},
+		{"delete", func(db *gorm.DB) error {
+			return db.Delete(&Stock{}, 1).Error
+		}},
+		{"cond", func(db *gorm.DB) error {
+			var rows []map[string]any
+			return db.Table("stock").Select("id, " + r.IfNull() + "(name, '') AS name, " + h.Cond("qty > 10", "'many'", "'few'") + " AS level").Find(&rows).Error
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(r.DB.DB()); err != nil {
+				t.Fatal(err)
+			}
+			Check(t, want, tt.name, r.Take())
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Package dbbasetest 以 DryRun 生成 SQL，供各数据库插件比对方言的语句快照，不需要连接数据库
+package dbbasetest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/livexy/plugins/dbbase"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// Snapshots 用例名到期望语句的快照，一个用例生成多条语句时以 ";\n" 连接
+type Snapshots map[string]string
+
+// Suite 一种方言的全部快照，按 Upsert、Helper、CRUD 分组
+type Suite struct {
+	Upsert Snapshots
+	Helper Snapshots
+	CRUD   Snapshots
+}
+
+// Run 依次比对各组快照，各数据库插件只需提供 DryRun 实例与本方言的快照，为 nil 的分组跳过
+func Run(t *testing.T, r *Recorder, want Suite) {
+	groups := []struct {
+		name string
+		fn   func(t *testing.T, r *Recorder, want Snapshots)
+		want Snapshots
+	}{
+		{"upsert", upsert, want.Upsert},
+		{"helper", helper, want.Helper},
+		{"crud", crud, want.CRUD},
+	}
+	for _, g := range groups {
+		if g.want != nil {
+			t.Run(g.name, func(t *testing.T) { g.fn(t, r, g.want) })
+		}
+	}
+}
+
+// Recorder DryRun 实例与其生成的语句
+type Recorder struct {
+	*dbbase.DB
+	sqls []string
+}
+
+// Open 以 DryRun 方式打开连接，dialector 初始化时不得访问数据库
+func Open(t *testing.T, dialect dbbase.Dialect, dialector gorm.Dialector) *Recorder {
+	t.Helper()
+	db, err := gorm.Open(dialector, &gorm.Config{
+		NamingStrategy:         schema.NamingStrategy{SingularTable: true},
+		SkipDefaultTransaction: true,
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Recorder{DB: dbbase.NewWithDB(dialect, db)}
+	record := func(tx *gorm.DB) {
+		r.sqls = append(r.sqls, tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+	}
+	cb := db.Callback()
+	err = errors.Join(
+		cb.Create().After("gorm:create").Register("dbbasetest:record", record),
+		cb.Query().After("gorm:query").Register("dbbasetest:record", record),
+		cb.Update().After("gorm:update").Register("dbbasetest:record", record),
+		cb.Delete().After("gorm:delete").Register("dbbasetest:record", record),
+		cb.Raw().After("gorm:raw").Register("dbbasetest:record", record),
+		cb.Row().After("gorm:row").Register("dbbasetest:record", record),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// Take 取出并清空已生成的语句
+func (r *Recorder) Take() string {
+	sql := strings.Join(r.sqls, ";\n")
+	r.sqls = nil
+	return sql
+}
+
+// Check 按用例名比对快照，未提供快照时输出生成的语句以便补充
+func Check(t *testing.T, want Snapshots, name, got string) {
+	t.Helper()
+	w, ok := want[name]
+	if !ok {
+		t.Errorf("缺少快照 %s：\n%s", name, got)
+		return
+	}
+	if got != w {
+		t.Errorf("%s\n got: %s\nwant: %s", name, got, w)
+	}
+}
@@ -0,0 +1,48 @@
+package dbbasetest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/livexy/plugins/dbbase"
+)
+
+// 比对 dbbase.Helper 的片段组成的语句
+// 用例：date 日期截断、格式化、加减与差值，string 拼接、截取与忽略大小写匹配，
+// json 取出 JSON 字段与布尔字面量，aggregate 带分隔符的字符串聚合，paging 随机排序与分页
+func helper(t *testing.T, r *Recorder, want Snapshots) {
+	h := r.Helper()
+	tests := []struct {
+		name string
+		sql  string
+		args []any
+	}{
+		{"date", "SELECT " + strings.Join([]string{
+			h.DateTrunc(dbbase.Day, "created_at"),
+			h.DateTrunc(dbbase.Second, "created_at"),
+			h.DateFormat("created_at", "YYYY-MM-DD HH:mm:ss"),
+			h.DateAdd("created_at", -1, dbbase.Month),
+			h.DateAdd("created_at", 2, dbbase.Hour),
+			h.DateDiff(dbbase.Day, "created_at", "updated_at"),
+			h.DateDiff(dbbase.Month, "created_at", "updated_at"),
+		}, ", ") + " FROM event", nil},
+		{"string", "SELECT " + strings.Join([]string{
+			h.Concat("first_name", "' '", "last_name"),
+			h.Substring("name", 2, 3),
+			h.Substring("name", 2, 0),
+		}, ", ") + " FROM person WHERE " + h.ILike("name"), []any{"%it's%"}},
+		{"json", "SELECT " + h.JSONExtract("data", "user.name") + " FROM event WHERE active = " + h.Bool(true), nil},
+		{"aggregate", "SELECT dept, " + h.GroupConcatSep("name", "; ", "name") + " FROM person GROUP BY dept", nil},
+		{"paging", "SELECT id FROM event ORDER BY " + h.RandomOrder() + " " + h.Limit(10, 20) +
+			";\nSELECT id FROM event ORDER BY id " + h.Limit(0, 5), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rows []map[string]any
+			if err := r.DB.DB().Raw(tt.sql, tt.args...).Find(&rows).Error; err != nil {
+				t.Fatal(err)
+			}
+			Check(t, want, tt.name, r.Take())
+		})
+	}
+}
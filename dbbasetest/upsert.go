@@ -0,0 +1,43 @@
+package dbbasetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/livexy/plugins/dbbase"
+)
+
+// Stock 快照用例使用的模型
+type Stock struct {
+	ID   int64 `gorm:"primaryKey"`
+	Name string
+	Qty  int
+}
+
+// 比对 Upsert 在各选项下生成的语句，返回错误时快照为 "error: " 加错误信息
+// 用例：model 模型主键冲突覆盖、autoIncrement 自增主键为零、updates 覆盖指定列、increments 累加、doNothing 保留原记录
+func upsert(t *testing.T, r *Recorder, want Snapshots) {
+	rows := func() []map[string]any {
+		return []map[string]any{{"id": 1, "name": "a", "qty": 2}, {"id": 2, "name": "b", "qty": 3}}
+	}
+	tests := []struct {
+		name   string
+		values any
+		opt    dbbase.Upsert
+	}{
+		{"model", &Stock{ID: 1, Name: "a", Qty: 2}, dbbase.Upsert{}},
+		{"autoIncrement", &Stock{Name: "a", Qty: 2}, dbbase.Upsert{}},
+		{"updates", rows(), dbbase.Upsert{Table: "stock", Columns: []string{"id"}, Updates: []string{"name"}}},
+		{"increments", rows(), dbbase.Upsert{Table: "stock", Columns: []string{"id"}, Increments: map[string]any{"qty": 1}}},
+		{"doNothing", rows(), dbbase.Upsert{Table: "stock", Columns: []string{"id"}, DoNothing: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := r.Upsert(context.Background(), tt.values, tt.opt); err != nil {
+				Check(t, want, tt.name, "error: "+err.Error())
+				return
+			}
+			Check(t, want, tt.name, r.Take())
+		})
+	}
+}
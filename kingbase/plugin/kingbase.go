@@ -0,0 +1,40 @@
+package plugin
+
+import (
+	"github.com/livexy/plugin/dber"
+	"github.com/livexy/plugins/dbbase"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Kingbase 方言，人大金仓兼容 PostgreSQL 协议，使用 pgx 驱动连接（默认端口 54321）
+var dialect = dbbase.Dialect{
+	Name: "kingbase",
+	Open: func(dsn string) gorm.Dialector {
+		return postgres.New(postgres.Config{DSN: dsn, PreferSimpleProtocol: true})
+	},
+	Slots: 32767,
+
+	// 空值判断使用 coalesce，条件判断与 PostgreSQL 插件一致使用 iif
+	IfNull: "coalesce",
+	If:     "iif",
+	GroupConcat: func(field string) string {
+		return "string_agg(" + field + ", ',')"
+	},
+	Helper: dbbase.PostgresHelper{},
+	// 针对 PostgreSQL 的 excluded 语法
+	ExAdd: func(field string, val any) any {
+		return gorm.Expr(`"excluded"."`+field+`"+?`, val)
+	},
+
+	IDMode:       dbbase.IDReturning,
+	LastInsertID: "select lastval() as id",
+
+	Upsert: dbbase.UpsertOnConflict,
+}
+
+// New 创建一个新的 Kingbase 数据库适配实例
+func New() dber.Dber {
+	return dbbase.New(dialect)
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"github.com/livexy/plugin/cacher"
+	plug "github.com/livexy/plugins/memory/plugin"
+
+	"go.uber.org/zap"
+)
+
+var Plugin plugin
+type plugin struct{}
+
+func(p plugin) New(cfg cacher.CacheConfig, logger *zap.Logger) (cacher.Cacher, error) {
+	return plug.NewMemoryCache(cfg, logger)
+}
+
+func(p plugin) NewWithSnapshot(cfg cacher.CacheConfig, snapshot string, logger *zap.Logger) (cacher.Cacher, error) {
+	return plug.NewMemoryCacheWithSnapshot(cfg, snapshot, logger)
+}
@@ -0,0 +1,80 @@
+package plugin
+
+// 按 Redis 的通配规则匹配，支持 * ? [abc] [^a] [a-z] 与 \ 转义
+func globMatch(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := range len(s) + 1 {
+				if globMatch(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+			s = s[1:]
+		case '[':
+			if len(s) == 0 {
+				return false
+			}
+			var ok bool
+			ok, pattern = matchClass(pattern[1:], s[0])
+			if !ok {
+				return false
+			}
+			s = s[1:]
+			continue
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(s) == 0 || pattern[0] != s[0] {
+				return false
+			}
+			s = s[1:]
+		}
+		pattern = pattern[1:]
+	}
+	return len(s) == 0
+}
+
+// 匹配 [ 之后的字符集，返回是否匹配与 ] 之后剩余的模式，缺少 ] 时字符集延续到结尾
+func matchClass(pattern string, c byte) (bool, string) {
+	not := len(pattern) > 0 && pattern[0] == '^'
+	if not {
+		pattern = pattern[1:]
+	}
+	match := false
+	for len(pattern) > 0 && pattern[0] != ']' {
+		switch {
+		case pattern[0] == '\\' && len(pattern) > 1:
+			pattern = pattern[1:]
+			match = match || pattern[0] == c
+		case len(pattern) > 2 && pattern[1] == '-':
+			lo, hi := pattern[0], pattern[2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			match = match || (c >= lo && c <= hi)
+			pattern = pattern[2:]
+		default:
+			match = match || pattern[0] == c
+		}
+		pattern = pattern[1:]
+	}
+	if len(pattern) > 0 {
+		pattern = pattern[1:]
+	}
+	return match != not, pattern
+}
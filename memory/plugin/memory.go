@@ -0,0 +1,1001 @@
+package plugin
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"math/bits"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/livexy/plugin/cacher"
+
+	"go.uber.org/zap"
+)
+
+const lockSeconds = 10
+
+// 过期键清理间隔
+const evictInterval = time.Second
+
+var (
+	errWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	errNotInt    = errors.New("ERR value is not an integer or out of range")
+	errBitValue  = errors.New("ERR bit is not an integer or out of range")
+	errHashArgs  = errors.New("ERR wrong number of arguments for 'hset' command")
+	errPushArgs  = errors.New("ERR wrong number of arguments for 'push' command")
+	errOverflow  = errors.New("ERR increment or decrement would overflow")
+)
+
+// 值类型
+type kind uint8
+
+const (
+	kindString kind = iota
+	kindHash
+	kindList
+)
+
+// 键值，字段导出以便快照编码
+type entry struct {
+	Kind   kind
+	Str    []byte
+	Hash   map[string][]byte
+	List   [][]byte
+	Expire int64 // 过期时间 UnixNano，0 永不过期
+}
+
+func (e *entry) expired(now int64) bool {
+	return e.Expire > 0 && e.Expire <= now
+}
+
+type memoryCache struct {
+	mu     sync.Mutex
+	data   map[string]*entry
+	logger *zap.Logger
+	prefix string
+
+	snapshot string // 快照文件，为空时不持久化
+	dirty    bool   // 上次快照后是否有修改
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewMemoryCache 创建进程内缓存，与 Redis 缓存接口一致，用于单元测试和单机部署
+// 不持久化，cfg.Path 为插件加载路径，不作为快照文件
+func NewMemoryCache(cfg cacher.CacheConfig, logger *zap.Logger) (cacher.Cacher, error) {
+	return NewMemoryCacheWithSnapshot(cfg, "", logger)
+}
+
+// NewMemoryCacheWithSnapshot 创建带快照的进程内缓存
+// snapshot 不为空时启动加载该文件，定期及关闭时写回
+func NewMemoryCacheWithSnapshot(cfg cacher.CacheConfig, snapshot string, logger *zap.Logger) (cacher.Cacher, error) {
+	cache := &memoryCache{
+		data: map[string]*entry{}, logger: logger, prefix: cfg.Prefix, snapshot: snapshot,
+		stop: make(chan struct{}), done: make(chan struct{}),
+	}
+	if len(cache.snapshot) > 0 {
+		if err := cache.load(); err != nil {
+			return nil, err
+		}
+	}
+	go cache.janitor()
+	return cache, nil
+}
+
+// 自动加前缀
+func (cache *memoryCache) getKey(key string) string {
+	return cache.prefix + ":" + key
+}
+
+// 批量加前缀
+func (cache *memoryCache) getKeys(keys []string) []string {
+	list := make([]string, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, cache.getKey(key))
+	}
+	return list
+}
+
+// 定期清理过期键与写快照
+func (cache *memoryCache) janitor() {
+	defer close(cache.done)
+	ticker := time.NewTicker(evictInterval)
+	defer ticker.Stop()
+	last := time.Now()
+	for {
+		select {
+		case <-cache.stop:
+			return
+		case now := <-ticker.C:
+			cache.evict(now.UnixNano())
+			if len(cache.snapshot) > 0 && now.Sub(last) >= snapshotInterval {
+				last = now
+				if err := cache.save(); err != nil {
+					cache.logger.Error("Memory 快照：", zap.String("file", cache.snapshot), zap.Error(err))
+				}
+			}
+		}
+	}
+}
+
+// 清理过期键
+func (cache *memoryCache) evict(now int64) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	for key, e := range cache.data {
+		if e.expired(now) {
+			delete(cache.data, key)
+			cache.dirty = true
+		}
+	}
+}
+
+// 读取未过期的键，调用方需持有锁
+func (cache *memoryCache) get(ckey string) *entry {
+	e, ok := cache.data[ckey]
+	if !ok {
+		return nil
+	}
+	if e.expired(time.Now().UnixNano()) {
+		delete(cache.data, ckey)
+		cache.dirty = true
+		return nil
+	}
+	return e
+}
+
+// 读取指定类型的键，调用方需持有锁
+func (cache *memoryCache) getKind(ckey string, k kind) (*entry, error) {
+	e := cache.get(ckey)
+	if e != nil && e.Kind != k {
+		return nil, errWrongType
+	}
+	return e, nil
+}
+
+// 读取或创建指定类型的键，调用方需持有锁
+func (cache *memoryCache) getOrCreate(ckey string, k kind) (*entry, error) {
+	e, err := cache.getKind(ckey, k)
+	if err != nil || e != nil {
+		return e, err
+	}
+	e = &entry{Kind: k}
+	switch k {
+	case kindHash:
+		e.Hash = map[string][]byte{}
+	}
+	cache.data[ckey] = e
+	return e, nil
+}
+
+// 与 go-redis 一致的参数序列化
+func toBytes(value any) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return []byte{}, nil
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return slices.Clone(v), nil
+	case int:
+		return strconv.AppendInt(nil, int64(v), 10), nil
+	case int8:
+		return strconv.AppendInt(nil, int64(v), 10), nil
+	case int16:
+		return strconv.AppendInt(nil, int64(v), 10), nil
+	case int32:
+		return strconv.AppendInt(nil, int64(v), 10), nil
+	case int64:
+		return strconv.AppendInt(nil, v, 10), nil
+	case uint:
+		return strconv.AppendUint(nil, uint64(v), 10), nil
+	case uint8:
+		return strconv.AppendUint(nil, uint64(v), 10), nil
+	case uint16:
+		return strconv.AppendUint(nil, uint64(v), 10), nil
+	case uint32:
+		return strconv.AppendUint(nil, uint64(v), 10), nil
+	case uint64:
+		return strconv.AppendUint(nil, v, 10), nil
+	case float32:
+		return strconv.AppendFloat(nil, float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.AppendFloat(nil, v, 'f', -1, 64), nil
+	case bool:
+		if v {
+			return []byte("1"), nil
+		}
+		return []byte("0"), nil
+	case time.Time:
+		return v.AppendFormat(nil, time.RFC3339Nano), nil
+	case time.Duration:
+		return strconv.AppendInt(nil, v.Nanoseconds(), 10), nil
+	case encoding.BinaryMarshaler:
+		return v.MarshalBinary()
+	case fmt.Stringer:
+		return []byte(v.String()), nil
+	}
+	return nil, fmt.Errorf("memory: can't marshal %T (implement encoding.BinaryMarshaler)", value)
+}
+
+// 过期时间，0 永不过期
+func deadline(expiration time.Duration) int64 {
+	if expiration <= 0 {
+		return 0
+	}
+	return time.Now().Add(expiration).UnixNano()
+}
+
+// 写入字符串，nx、xx 控制仅不存在或仅存在时写入
+func (cache *memoryCache) set(key string, value any, expiration time.Duration, nx, xx bool) (bool, error) {
+	val, err := toBytes(value)
+	if err != nil {
+		return false, err
+	}
+	ckey := cache.getKey(key)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e := cache.get(ckey)
+	if (nx && e != nil) || (xx && e == nil) {
+		return false, nil
+	}
+	cache.data[ckey] = &entry{Kind: kindString, Str: val, Expire: deadline(expiration)}
+	cache.dirty = true
+	return true, nil
+}
+
+// 保存数据
+func (cache *memoryCache) Set(key string, value any, expiration time.Duration) bool {
+	_, err := cache.set(key, value, expiration, false, false)
+	if err != nil {
+		cache.logger.Error("Memory Set：", zap.String("key", key), zap.Any("value", value), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// 保存数据
+func (cache *memoryCache) SetNX(key string, value any, expiration time.Duration) bool {
+	ok, err := cache.set(key, value, expiration, true, false)
+	if err != nil {
+		cache.logger.Error("Memory SetNX：", zap.String("key", key), zap.Any("value", value), zap.Error(err))
+		return false
+	}
+	return ok
+}
+func (cache *memoryCache) SetXX(key string, value any, expiration time.Duration) bool {
+	ok, err := cache.set(key, value, expiration, false, true)
+	if err != nil {
+		cache.logger.Error("Memory SetXX：", zap.String("key", key), zap.Any("value", value), zap.Error(err))
+		return false
+	}
+	return ok
+}
+
+// 累加，保留原过期时间
+func (cache *memoryCache) incrBy(key string, val int64) (int64, error) {
+	ckey := cache.getKey(key)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e, err := cache.getKind(ckey, kindString)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	if e != nil {
+		n, err = strconv.ParseInt(string(e.Str), 10, 64)
+		if err != nil {
+			return 0, errNotInt
+		}
+	} else {
+		e = &entry{Kind: kindString}
+		cache.data[ckey] = e
+	}
+	if n, err = addInt(n, val); err != nil {
+		return 0, err
+	}
+	e.Str = strconv.AppendInt(nil, n, 10)
+	cache.dirty = true
+	return n, nil
+}
+
+// 整数相加，溢出时返回错误
+func addInt(n, incr int64) (int64, error) {
+	if (incr > 0 && n > math.MaxInt64-incr) || (incr < 0 && n < math.MinInt64-incr) {
+		return 0, errOverflow
+	}
+	return n + incr, nil
+}
+
+// 保存数据
+func (cache *memoryCache) Incr(key string) int64 {
+	val, err := cache.incrBy(key, 1)
+	if err != nil {
+		cache.logger.Error("Memory Incr：", zap.String("key", key), zap.Error(err))
+		return 0
+	}
+	return val
+}
+func (cache *memoryCache) Decr(key string) int64 {
+	val, err := cache.incrBy(key, -1)
+	if err != nil {
+		cache.logger.Error("Memory Decr：", zap.String("key", key), zap.Error(err))
+		return 0
+	}
+	return val
+}
+
+// 保存数据
+func (cache *memoryCache) IncrBy(key string, val int64) int64 {
+	result, err := cache.incrBy(key, val)
+	if err != nil {
+		cache.logger.Error("Memory IncrBy：", zap.String("key", key), zap.Int64("value", val), zap.Error(err))
+		return 0
+	}
+	return result
+}
+func (cache *memoryCache) DecrBy(key string, val int64) int64 {
+	result, err := cache.incrBy(key, -val)
+	if err != nil {
+		cache.logger.Error("Memory DecrBy：", zap.String("key", key), zap.Int64("value", val), zap.Error(err))
+		return 0
+	}
+	return result
+}
+
+// KEY是否存在，重复的键重复计数
+func (cache *memoryCache) Exists(keys ...string) int64 {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	var n int64
+	for _, key := range keys {
+		if cache.get(cache.getKey(key)) != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// 读取字符串
+func (cache *memoryCache) getString(key string) ([]byte, bool, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e, err := cache.getKind(cache.getKey(key), kindString)
+	if err != nil || e == nil {
+		return nil, false, err
+	}
+	return slices.Clone(e.Str), true, nil
+}
+
+// 获取数据 string
+func (cache *memoryCache) Get(key string) string {
+	val, _, err := cache.getString(key)
+	if err != nil {
+		return ""
+	}
+	return string(val)
+}
+
+// 键不存在或类型不符时对应位置为 nil
+func (cache *memoryCache) MGet(keys ...string) []any {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	vals := make([]any, 0, len(keys))
+	for _, key := range keys {
+		e := cache.get(cache.getKey(key))
+		if e == nil || e.Kind != kindString {
+			vals = append(vals, nil)
+			continue
+		}
+		vals = append(vals, string(e.Str))
+	}
+	return vals
+}
+
+// 获取数据 bytes
+func (cache *memoryCache) GetBytes(key string) []byte {
+	val, ok, err := cache.getString(key)
+	if err != nil || !ok {
+		return nil
+	}
+	return val
+}
+
+// 获取INT数据
+func (cache *memoryCache) GetInt(key string) int {
+	ival, err := strconv.Atoi(cache.Get(key))
+	if err != nil {
+		return 0
+	}
+	return ival
+}
+
+// 获取INT64数据
+func (cache *memoryCache) GetInt64(key string) int64 {
+	ival, err := strconv.ParseInt(cache.Get(key), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return ival
+}
+
+// 持久化 不过期存储
+func (cache *memoryCache) GetSet(key string, value any) string {
+	val, err := toBytes(value)
+	if err != nil {
+		cache.logger.Error("Memory GetSet：", zap.String("key", key), zap.Any("value", value), zap.Error(err))
+		return ""
+	}
+	ckey := cache.getKey(key)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e, err := cache.getKind(ckey, kindString)
+	if err != nil {
+		cache.logger.Error("Memory GetSet：", zap.String("key", key), zap.Any("value", value), zap.Error(err))
+		return ""
+	}
+	cache.data[ckey] = &entry{Kind: kindString, Str: val}
+	cache.dirty = true
+	if e == nil {
+		return ""
+	}
+	return string(e.Str)
+}
+
+// 批量获取KEY 返回带前缀的完整键
+func (cache *memoryCache) GetPatternKeys(prefix string) []string {
+	return cache.GetPatternScan(prefix)
+}
+
+// 批量获取KEY 返回带前缀的完整键，prefix 与 Redis SCAN MATCH 相同支持 * ? [] 通配
+func (cache *memoryCache) GetPatternScan(prefix string) []string {
+	match := cache.getKey(prefix) + "*"
+	now := time.Now().UnixNano()
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	list := []string{}
+	for key, e := range cache.data {
+		if globMatch(match, key) && !e.expired(now) {
+			list = append(list, key)
+		}
+	}
+	slices.Sort(list)
+	return list
+}
+
+// 删除完整键
+func (cache *memoryCache) del(keys []string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	for _, key := range keys {
+		if _, ok := cache.data[key]; ok {
+			delete(cache.data, key)
+			cache.dirty = true
+		}
+	}
+}
+
+// 自动加前缀 批量删除KEY
+func (cache *memoryCache) Delete(keys ...string) bool {
+	cache.del(cache.getKeys(keys))
+	return true
+}
+func (cache *memoryCache) Unlink(keys ...string) bool {
+	return cache.Delete(keys...)
+}
+
+// 无前缀 批量删除KEY
+func (cache *memoryCache) DeleteKeys(keys ...string) bool {
+	cache.del(keys)
+	return true
+}
+func (cache *memoryCache) UnlinkKeys(keys ...string) bool {
+	return cache.DeleteKeys(keys...)
+}
+
+// 加锁 返回 true 表示已被锁定
+func (cache *memoryCache) LockStart(key string, args ...int) bool {
+	seconds := lockSeconds
+	if len(args) > 0 {
+		seconds = args[0]
+	}
+	return !cache.SetNX("Lock:"+key, 1, time.Duration(seconds)*time.Second)
+}
+
+// 解锁
+func (cache *memoryCache) LockEnd(key string) {
+	cache.Delete("Lock:" + key)
+}
+
+// 关闭 停止清理，配置快照时写回磁盘
+func (cache *memoryCache) Close() {
+	select {
+	case <-cache.stop:
+		return
+	default:
+	}
+	close(cache.stop)
+	<-cache.done
+	if len(cache.snapshot) > 0 {
+		if err := cache.save(); err != nil {
+			cache.logger.Error("Memory 快照：", zap.String("file", cache.snapshot), zap.Error(err))
+		}
+	}
+}
+
+// 存在
+func (cache *memoryCache) HExists(key, field string) bool {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e, err := cache.getKind(cache.getKey(key), kindHash)
+	if err != nil {
+		cache.logger.Error("Memory HExists：", zap.String("key", key), zap.String("field", field), zap.Error(err))
+		return false
+	}
+	if e == nil {
+		return false
+	}
+	_, ok := e.Hash[field]
+	return ok
+}
+
+// 读取字段
+func (cache *memoryCache) hget(key, field string) ([]byte, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e, err := cache.getKind(cache.getKey(key), kindHash)
+	if err != nil || e == nil {
+		return nil, false
+	}
+	val, ok := e.Hash[field]
+	return slices.Clone(val), ok
+}
+
+// 获取数据 string
+func (cache *memoryCache) HGet(key, field string) string {
+	val, _ := cache.hget(key, field)
+	return string(val)
+}
+
+// 获取数据 bytes
+func (cache *memoryCache) HGetBytes(key, field string) []byte {
+	val, ok := cache.hget(key, field)
+	if !ok {
+		return nil
+	}
+	return val
+}
+
+// 获取INT64数据
+func (cache *memoryCache) HGetInt64(key, field string) int64 {
+	ival, err := strconv.ParseInt(cache.HGet(key, field), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return ival
+}
+func (cache *memoryCache) HGetAll(key string) map[string]string {
+	all := make(map[string]string)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e, err := cache.getKind(cache.getKey(key), kindHash)
+	if err != nil || e == nil {
+		return all
+	}
+	for field, val := range e.Hash {
+		all[field] = string(val)
+	}
+	return all
+}
+func (cache *memoryCache) HKeys(key string) []string {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e, err := cache.getKind(cache.getKey(key), kindHash)
+	if err != nil {
+		cache.logger.Error("Memory HKeys：", zap.String("key", key), zap.Error(err))
+		return nil
+	}
+	if e == nil {
+		return []string{}
+	}
+	return slices.Sorted(maps.Keys(e.Hash))
+}
+func (cache *memoryCache) HLen(key string) int64 {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e, err := cache.getKind(cache.getKey(key), kindHash)
+	if err != nil {
+		cache.logger.Error("Memory HLen：", zap.String("key", key), zap.Error(err))
+		return 0
+	}
+	if e == nil {
+		return 0
+	}
+	return int64(len(e.Hash))
+}
+
+// 展开 HSet 参数，与 go-redis 一致支持键值对、切片和 map
+func hashArgs(values []any) ([]any, error) {
+	if len(values) == 1 {
+		switch v := values[0].(type) {
+		case []string:
+			args := make([]any, 0, len(v))
+			for _, s := range v {
+				args = append(args, s)
+			}
+			values = args
+		case []any:
+			values = v
+		case map[string]any:
+			args := make([]any, 0, len(v)*2)
+			for field, val := range v {
+				args = append(args, field, val)
+			}
+			values = args
+		case map[string]string:
+			args := make([]any, 0, len(v)*2)
+			for field, val := range v {
+				args = append(args, field, val)
+			}
+			values = args
+		}
+	}
+	if len(values) == 0 || len(values)%2 != 0 {
+		return nil, errHashArgs
+	}
+	return values, nil
+}
+
+// 保存 返回新增字段数
+func (cache *memoryCache) hset(key string, values []any) (int64, error) {
+	args, err := hashArgs(values)
+	if err != nil {
+		return 0, err
+	}
+	fields := make([]string, 0, len(args)/2)
+	vals := make([][]byte, 0, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		field, ferr := toBytes(args[i])
+		if ferr != nil {
+			return 0, ferr
+		}
+		val, verr := toBytes(args[i+1])
+		if verr != nil {
+			return 0, verr
+		}
+		fields = append(fields, string(field))
+		vals = append(vals, val)
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e, err := cache.getOrCreate(cache.getKey(key), kindHash)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	for i, field := range fields {
+		if _, ok := e.Hash[field]; !ok {
+			n++
+		}
+		e.Hash[field] = vals[i]
+	}
+	cache.dirty = true
+	return n, nil
+}
+
+// 保存
+func (cache *memoryCache) HSet(key string, values ...any) int64 {
+	val, err := cache.hset(key, values)
+	if err != nil {
+		cache.logger.Error("Memory HSet：", zap.String("key", key), zap.Any("value", values), zap.Error(err))
+		return 0
+	}
+	return val
+}
+func (cache *memoryCache) HIncrBy(key, field string, incr int64) int64 {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e, err := cache.getOrCreate(cache.getKey(key), kindHash)
+	if err != nil {
+		cache.logger.Error("Memory HIncrBy：", zap.String("key", key), zap.String("field", field), zap.Int64("value", incr), zap.Error(err))
+		return 0
+	}
+	var n int64
+	if val, ok := e.Hash[field]; ok {
+		n, err = strconv.ParseInt(string(val), 10, 64)
+		if err != nil {
+			cache.logger.Error("Memory HIncrBy：", zap.String("key", key), zap.String("field", field), zap.Int64("value", incr), zap.Error(errNotInt))
+			return 0
+		}
+	}
+	if n, err = addInt(n, incr); err != nil {
+		cache.logger.Error("Memory HIncrBy：", zap.String("key", key), zap.String("field", field), zap.Int64("value", incr), zap.Error(err))
+		return 0
+	}
+	e.Hash[field] = strconv.AppendInt(nil, n, 10)
+	cache.dirty = true
+	return n
+}
+
+// 删除字段，字段全部删除后移除键
+func (cache *memoryCache) HDel(key string, fields ...string) int64 {
+	ckey := cache.getKey(key)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e, err := cache.getKind(ckey, kindHash)
+	if err != nil {
+		cache.logger.Error("Memory HDel：", zap.String("key", key), zap.Strings("fields", fields), zap.Error(err))
+		return 0
+	}
+	if e == nil {
+		return 0
+	}
+	var n int64
+	for _, field := range fields {
+		if _, ok := e.Hash[field]; ok {
+			delete(e.Hash, field)
+			n++
+		}
+	}
+	if len(e.Hash) == 0 {
+		delete(cache.data, ckey)
+	}
+	if n > 0 {
+		cache.dirty = true
+	}
+	return n
+}
+
+// 清空全部数据
+func (cache *memoryCache) FlushDB() bool {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.data = map[string]*entry{}
+	cache.dirty = true
+	return true
+}
+
+// 设置过期时间，键不存在时返回 false，时间已过时直接删除
+func (cache *memoryCache) expireAt(key string, tm time.Time) bool {
+	ckey := cache.getKey(key)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e := cache.get(ckey)
+	if e == nil {
+		return false
+	}
+	if !tm.After(time.Now()) {
+		delete(cache.data, ckey)
+	} else {
+		e.Expire = tm.UnixNano()
+	}
+	cache.dirty = true
+	return true
+}
+
+func (cache *memoryCache) Expire(key string, expiration time.Duration) bool {
+	return cache.expireAt(key, time.Now().Add(expiration))
+}
+func (cache *memoryCache) PExpire(key string, expiration time.Duration) bool {
+	return cache.expireAt(key, time.Now().Add(expiration))
+}
+func (cache *memoryCache) ExpireAt(key string, tm time.Time) bool {
+	return cache.expireAt(key, tm)
+}
+func (cache *memoryCache) PExpireAt(key string, tm time.Time) bool {
+	return cache.expireAt(key, tm)
+}
+
+// 位操作按 Redis 约定，偏移 0 为首字节最高位
+func (cache *memoryCache) GetBit(key string, offset int64) int64 {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e, err := cache.getKind(cache.getKey(key), kindString)
+	if err != nil {
+		cache.logger.Error("Memory GetBit：", zap.String("key", key), zap.Int64("offset", offset), zap.Error(err))
+		return 0
+	}
+	if e == nil || offset < 0 || offset/8 >= int64(len(e.Str)) {
+		return 0
+	}
+	return int64(e.Str[offset/8]>>(7-offset%8)) & 1
+}
+
+// 设置位，返回原值
+func (cache *memoryCache) SetBit(key string, offset int64, val int) int64 {
+	if offset < 0 || (val != 0 && val != 1) {
+		cache.logger.Error("Memory SetBit：", zap.String("key", key), zap.Int64("offset", offset), zap.Int("value", val), zap.Error(errBitValue))
+		return 0
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e, err := cache.getOrCreate(cache.getKey(key), kindString)
+	if err != nil {
+		cache.logger.Error("Memory SetBit：", zap.String("key", key), zap.Int64("offset", offset), zap.Int("value", val), zap.Error(err))
+		return 0
+	}
+	idx := offset / 8
+	if idx >= int64(len(e.Str)) {
+		e.Str = append(e.Str, make([]byte, idx+1-int64(len(e.Str)))...)
+	}
+	mask := byte(1) << (7 - offset%8)
+	old := int64(0)
+	if e.Str[idx]&mask != 0 {
+		old = 1
+	}
+	if val == 1 {
+		e.Str[idx] |= mask
+	} else {
+		e.Str[idx] &^= mask
+	}
+	cache.dirty = true
+	return old
+}
+
+// 统计字节区间 [start, end] 内的置位数，支持负数下标
+func (cache *memoryCache) BitCount(key string, start, end int64) int64 {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e, err := cache.getKind(cache.getKey(key), kindString)
+	if err != nil {
+		cache.logger.Error("Memory BitCount：", zap.String("key", key), zap.Error(err))
+		return 0
+	}
+	if e == nil {
+		return 0
+	}
+	lo, hi, ok := bounds(start, end, len(e.Str))
+	if !ok {
+		return 0
+	}
+	var n int64
+	for _, b := range e.Str[lo : hi+1] {
+		n += int64(bits.OnesCount8(b))
+	}
+	return n
+}
+
+// 按 Redis 规则换算闭区间下标
+func bounds(start, end int64, size int) (int64, int64, bool) {
+	n := int64(size)
+	if start < 0 {
+		start = max(n+start, 0)
+	}
+	if end < 0 {
+		end = n + end
+	}
+	end = min(end, n-1)
+	if n == 0 || start > end {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+func (cache *memoryCache) LLen(key string) int64 {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e, err := cache.getKind(cache.getKey(key), kindList)
+	if err != nil {
+		cache.logger.Error("Memory LLen：", zap.String("key", key), zap.Error(err))
+		return 0
+	}
+	if e == nil {
+		return 0
+	}
+	return int64(len(e.List))
+}
+
+// 入列，left 为 true 时逐个插入头部
+func (cache *memoryCache) push(key string, left bool, values []any) (int64, error) {
+	if len(values) == 0 {
+		return 0, errPushArgs
+	}
+	vals := make([][]byte, 0, len(values))
+	for _, value := range values {
+		val, err := toBytes(value)
+		if err != nil {
+			return 0, err
+		}
+		vals = append(vals, val)
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e, err := cache.getOrCreate(cache.getKey(key), kindList)
+	if err != nil {
+		return 0, err
+	}
+	if left {
+		slices.Reverse(vals)
+		e.List = append(vals, e.List...)
+	} else {
+		e.List = append(e.List, vals...)
+	}
+	cache.dirty = true
+	return int64(len(e.List)), nil
+}
+
+// 出列，列表为空时移除键
+func (cache *memoryCache) pop(key string, left bool) ([]byte, error) {
+	ckey := cache.getKey(key)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e, err := cache.getKind(ckey, kindList)
+	if err != nil || e == nil || len(e.List) == 0 {
+		return nil, err
+	}
+	var val []byte
+	if left {
+		val, e.List = e.List[0], e.List[1:]
+	} else {
+		val, e.List = e.List[len(e.List)-1], e.List[:len(e.List)-1]
+	}
+	if len(e.List) == 0 {
+		delete(cache.data, ckey)
+	}
+	cache.dirty = true
+	return val, nil
+}
+
+func (cache *memoryCache) LPush(key string, values ...any) int64 {
+	val, err := cache.push(key, true, values)
+	if err != nil {
+		cache.logger.Error("Memory LPush：", zap.String("key", key), zap.Any("value", values), zap.Error(err))
+		return 0
+	}
+	return val
+}
+func (cache *memoryCache) LPop(key string) []byte {
+	val, err := cache.pop(key, true)
+	if err != nil {
+		cache.logger.Error("Memory LPop：", zap.String("key", key), zap.Error(err))
+		return nil
+	}
+	return val
+}
+func (cache *memoryCache) RPush(key string, values ...any) int64 {
+	val, err := cache.push(key, false, values)
+	if err != nil {
+		cache.logger.Error("Memory RPush：", zap.String("key", key), zap.Any("value", values), zap.Error(err))
+		return 0
+	}
+	return val
+}
+func (cache *memoryCache) RPop(key string) []byte {
+	val, err := cache.pop(key, false)
+	if err != nil {
+		cache.logger.Error("Memory RPop：", zap.String("key", key), zap.Error(err))
+		return nil
+	}
+	return val
+}
+
+// 区间 [start, stop] 内的元素，支持负数下标
+func (cache *memoryCache) LRange(key string, start, stop int64) []string {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	e, err := cache.getKind(cache.getKey(key), kindList)
+	if err != nil {
+		cache.logger.Error("Memory LRange：", zap.String("key", key), zap.Error(err))
+		return nil
+	}
+	list := []string{}
+	if e == nil {
+		return list
+	}
+	lo, hi, ok := bounds(start, stop, len(e.List))
+	if !ok {
+		return list
+	}
+	for _, val := range e.List[lo : hi+1] {
+		list = append(list, string(val))
+	}
+	return list
+}
+
+var _ cacher.Cacher = (*memoryCache)(nil)
@@ -0,0 +1,106 @@
+package plugin
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/livexy/plugin/cacher"
+	"github.com/livexy/plugins/cachertest"
+
+	"go.uber.org/zap"
+)
+
+func TestConformance(t *testing.T) {
+	cachertest.Run(t, cachertest.Options{
+		Prefix: "test",
+		New: func(t *testing.T) (cacher.Cacher, func(time.Duration)) {
+			c, err := NewMemoryCache(cacher.CacheConfig{Prefix: "test"}, zap.NewNop())
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(c.Close)
+			return c, time.Sleep
+		},
+	})
+}
+
+// 快照写入独立文件，Path 不参与持久化
+func TestSnapshot(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cache", "memory.snapshot")
+	cfg := cacher.CacheConfig{Prefix: "test", Path: t.TempDir()}
+	c, err := NewMemoryCacheWithSnapshot(cfg, file, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Set("a", "1", 0)
+	c.HSet("h", "f", "v")
+	c.Set("gone", "1", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	c.Close()
+
+	c, err = NewMemoryCacheWithSnapshot(cfg, file, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if c.Get("a") != "1" || c.HGet("h", "f") != "v" || c.Exists("gone") != 0 {
+		t.Fatal("snapshot not restored")
+	}
+
+	plain, err := NewMemoryCache(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plain.Close()
+	if plain.Exists("a") != 0 {
+		t.Fatal("NewMemoryCache should not load a snapshot")
+	}
+	entries, err := os.ReadDir(cfg.Path)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("plugin path modified: %v, %v", entries, err)
+	}
+}
+
+// HIncrBy 与 Redis 相同检查溢出，miniredis 不检查，故不在一致性测试中
+func TestHIncrByOverflow(t *testing.T) {
+	c, err := NewMemoryCache(cacher.CacheConfig{Prefix: "test"}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.HSet("h", "min", math.MinInt64)
+	if c.HIncrBy("h", "min", -1) != 0 || c.HGetInt64("h", "min") != math.MinInt64 {
+		t.Fatal("HIncrBy overflow should fail")
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*", "", true},
+		{"a*b", "axxb", true},
+		{"a*b", "axxc", false},
+		{"a**", "a", true},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"[abc]x", "bx", true},
+		{"[^abc]x", "bx", false},
+		{"[a-c]", "b", true},
+		{"[c-a]", "b", true},
+		{"[a-c]", "d", false},
+		{`[\]]`, "]", true},
+		{`a\*`, "a*", true},
+		{`a\*`, "ab", false},
+		{"[ab", "a", true},
+	}
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("globMatch(%q, %q) = %v", tt.pattern, tt.s, got)
+		}
+	}
+}
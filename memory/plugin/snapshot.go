@@ -0,0 +1,97 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// 快照写入间隔，仅在有修改时写入
+const snapshotInterval = time.Minute
+
+// 加载快照，文件不存在时忽略，已过期的键丢弃
+func (cache *memoryCache) load() error {
+	f, err := os.Open(cache.snapshot)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	data := map[string]*entry{}
+	err = gob.NewDecoder(bufio.NewReader(f)).Decode(&data)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UnixNano()
+	for key, e := range data {
+		if e.expired(now) {
+			delete(data, key)
+		}
+	}
+	cache.mu.Lock()
+	cache.data = data
+	cache.mu.Unlock()
+	return nil
+}
+
+// 写入快照，锁内只编码数据，释放锁后再写文件，写入失败时保留修改标记
+func (cache *memoryCache) save() error {
+	cache.mu.Lock()
+	if !cache.dirty {
+		cache.mu.Unlock()
+		return nil
+	}
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(cache.data)
+	if err == nil {
+		cache.dirty = false
+	}
+	cache.mu.Unlock()
+	if err == nil {
+		err = cache.write(buf.Bytes())
+	}
+	if err != nil {
+		cache.mu.Lock()
+		cache.dirty = true
+		cache.mu.Unlock()
+	}
+	return err
+}
+
+// 先写临时文件再替换，避免中途失败损坏原快照
+func (cache *memoryCache) write(data []byte) error {
+	err := os.MkdirAll(filepath.Dir(cache.snapshot), 0o755)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(cache.snapshot), filepath.Base(cache.snapshot)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	cerr := f.Close()
+	if err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, cache.snapshot)
+	}
+	if err != nil {
+		if rerr := os.Remove(tmp); rerr != nil && !errors.Is(rerr, fs.ErrNotExist) {
+			return errors.Join(err, rerr)
+		}
+		return err
+	}
+	return nil
+}
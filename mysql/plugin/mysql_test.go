@@ -0,0 +1,36 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/livexy/plugins/dbbasetest"
+
+	"gorm.io/driver/mysql"
+)
+
+func TestDryRun(t *testing.T) {
+	dbbasetest.Run(t, dbbasetest.Open(t, dialect, mysql.New(mysql.Config{DSN: "root@tcp(127.0.0.1:3306)/test", SkipInitializeWithVersion: true})), dbbasetest.Suite{
+		Upsert: dbbasetest.Snapshots{
+			"model":         "INSERT INTO `stock` (`name`, `qty`, `id`) VALUES ('a',2,1) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `qty` = VALUES(`qty`)",
+			"autoIncrement": "INSERT INTO `stock` (`name`, `qty`) VALUES ('a',2) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `qty` = VALUES(`qty`)",
+			"updates":       "INSERT INTO `stock` (`id`, `name`, `qty`) VALUES (1,'a',2), (2,'b',3) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)",
+			"increments":    "INSERT INTO `stock` (`id`, `name`, `qty`) VALUES (1,'a',2), (2,'b',3) ON DUPLICATE KEY UPDATE `qty` = `qty` + 1",
+			"doNothing":     "INSERT INTO `stock` (`id`, `name`, `qty`) VALUES (1,'a',2), (2,'b',3) ON DUPLICATE KEY UPDATE `id` = `id`",
+		},
+		Helper: dbbasetest.Snapshots{
+			"date":      `SELECT CAST(DATE_FORMAT(created_at, '%Y-%m-%d 00:00:00') AS DATETIME), CAST(DATE_FORMAT(created_at, '%Y-%m-%d %H:%i:%s') AS DATETIME), DATE_FORMAT(created_at, '%Y-%m-%d %H:%i:%s'), DATE_ADD(created_at, INTERVAL -1 MONTH), DATE_ADD(created_at, INTERVAL 2 HOUR), TIMESTAMPDIFF(DAY, created_at, updated_at), TIMESTAMPDIFF(MONTH, created_at, updated_at) FROM event`,
+			"string":    `SELECT CONCAT_WS('', first_name, ' ', last_name), SUBSTRING(name, 2, 3), SUBSTRING(name, 2) FROM person WHERE LOWER(name) LIKE LOWER('%it''s%')`,
+			"json":      `SELECT JSON_UNQUOTE(JSON_EXTRACT(data, '$.user.name')) FROM event WHERE active = 1`,
+			"aggregate": `SELECT dept, GROUP_CONCAT(name ORDER BY name SEPARATOR '; ') FROM person GROUP BY dept`,
+			"paging": `SELECT id FROM event ORDER BY RAND() LIMIT 10 OFFSET 20;
+SELECT id FROM event ORDER BY id LIMIT 18446744073709551615 OFFSET 5`,
+		},
+		CRUD: dbbasetest.Snapshots{
+			"create": "INSERT INTO `stock` (`name`,`qty`) VALUES ('a',1)",
+			"find":   "SELECT * FROM `stock` WHERE qty > 1 ORDER BY id LIMIT 10 OFFSET 20",
+			"update": "UPDATE `stock` SET `name`='b',`qty`=qty + 1 WHERE `id` = 1",
+			"delete": "DELETE FROM `stock` WHERE `stock`.`id` = 1",
+			"cond":   "SELECT id, ifnull(name, '') AS name, CASE WHEN qty > 10 THEN 'many' ELSE 'few' END AS level FROM `stock`",
+		},
+	})
+}
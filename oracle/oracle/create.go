@@ -0,0 +1,187 @@
+package oracle
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrOnConflict Oracle 不支持 ON CONFLICT
+var ErrOnConflict = errors.New("oracle 不支持 ON CONFLICT，请使用 MERGE")
+
+// Create 插入回调
+// Oracle 12c 不支持多行 VALUES，逐行插入；自增主键与 RETURNING 指定的列通过 RETURNING ... INTO 回填
+func Create(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	stmt := db.Statement
+	if stmt.Schema != nil && !stmt.Unscoped {
+		for _, c := range stmt.Schema.CreateClauses {
+			stmt.AddClause(c)
+		}
+	}
+	if stmt.SQL.Len() > 0 {
+		exec(db)
+		return
+	}
+	if _, ok := stmt.Clauses["ON CONFLICT"]; ok {
+		_ = db.AddError(ErrOnConflict)
+		return
+	}
+	values := callbacks.ConvertToCreateValues(stmt)
+	if db.Error != nil {
+		return
+	}
+	returning := returningColumns(stmt)
+	var affected int64
+	for i, row := range values.Values {
+		stmt.SQL.Reset()
+		stmt.Vars = nil
+		_, _ = stmt.WriteString("INSERT INTO ")
+		stmt.WriteQuoted(clause.Table{Name: stmt.Table})
+		if len(values.Columns) > 0 {
+			_, _ = stmt.WriteString(" (")
+			for j, column := range values.Columns {
+				if j > 0 {
+					_ = stmt.WriteByte(',')
+				}
+				stmt.WriteQuoted(column)
+			}
+			_, _ = stmt.WriteString(") VALUES (")
+			stmt.AddVar(stmt, row...)
+			_ = stmt.WriteByte(')')
+		} else if len(returning) > 0 {
+			_, _ = stmt.WriteString(" (")
+			stmt.WriteQuoted(returning[0])
+			_, _ = stmt.WriteString(") VALUES (DEFAULT)")
+		} else {
+			_ = db.AddError(gorm.ErrEmptySlice)
+			return
+		}
+		dests := make([]any, len(returning))
+		if len(returning) > 0 {
+			_, _ = stmt.WriteString(" RETURNING ")
+			for j, column := range returning {
+				if j > 0 {
+					_ = stmt.WriteByte(',')
+				}
+				stmt.WriteQuoted(column)
+			}
+			_, _ = stmt.WriteString(" INTO ")
+			for j, column := range returning {
+				if j > 0 {
+					_ = stmt.WriteByte(',')
+				}
+				dests[j] = newDest(stmt, column.Name)
+				stmt.AddVar(stmt, sql.Out{Dest: dests[j]})
+			}
+		}
+		if db.DryRun {
+			return
+		}
+		if !exec(db) {
+			return
+		}
+		affected += db.RowsAffected
+		backfill(db, i, returning, dests)
+	}
+	db.RowsAffected = affected
+	if stmt.Result != nil {
+		stmt.Result.RowsAffected = affected
+	}
+}
+
+// 执行已生成的语句
+func exec(db *gorm.DB) bool {
+	if db.DryRun || db.Error != nil {
+		return false
+	}
+	result, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...)
+	if db.AddError(err) != nil {
+		return false
+	}
+	db.RowsAffected, _ = result.RowsAffected()
+	if db.Statement.Result != nil {
+		db.Statement.Result.Result = result
+		db.Statement.Result.RowsAffected = db.RowsAffected
+	}
+	return true
+}
+
+// 需要回填的列，优先使用 RETURNING 子句，否则为有数据库默认值的主键
+func returningColumns(stmt *gorm.Statement) []clause.Column {
+	if c, ok := stmt.Clauses["RETURNING"]; ok {
+		if r, ok := c.Expression.(clause.Returning); ok && len(r.Columns) > 0 {
+			return r.Columns
+		}
+	}
+	if stmt.Schema == nil {
+		return nil
+	}
+	if field := stmt.Schema.PrioritizedPrimaryField; field != nil && field.HasDefaultValue && field.DefaultValueInterface == nil {
+		return []clause.Column{{Name: field.DBName}}
+	}
+	return nil
+}
+
+// 按字段类型创建 RETURNING INTO 的输出参数
+func newDest(stmt *gorm.Statement, name string) any {
+	var typ reflect.Type
+	if stmt.Schema != nil {
+		if field := stmt.Schema.LookUpField(name); field != nil {
+			typ = field.IndirectFieldType
+		}
+	}
+	if typ == nil {
+		return new(int64)
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(int64)
+	case reflect.Float32, reflect.Float64:
+		return new(float64)
+	case reflect.String:
+		return new(string)
+	}
+	if typ == reflect.TypeOf(time.Time{}) {
+		return new(time.Time)
+	}
+	return reflect.New(typ).Interface()
+}
+
+// 将输出参数回填到第 i 行
+func backfill(db *gorm.DB, i int, returning []clause.Column, dests []any) {
+	if len(returning) == 0 {
+		return
+	}
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		rv = reflect.Indirect(rv.Index(i))
+	}
+	for j, column := range returning {
+		val := reflect.ValueOf(dests[j]).Elem()
+		switch rv.Kind() {
+		case reflect.Map:
+			if rv.Type().Key().Kind() == reflect.String && rv.Type().Elem().Kind() == reflect.Interface {
+				rv.SetMapIndex(reflect.ValueOf(column.Name), val)
+			}
+		case reflect.Struct:
+			var field *schema.Field
+			if db.Statement.Schema != nil {
+				field = db.Statement.Schema.LookUpField(column.Name)
+			}
+			if field != nil {
+				_ = db.AddError(field.Set(db.Statement.Context, rv, val.Interface()))
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package oracle
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/migrator"
+)
+
+type Migrator struct {
+	migrator.Migrator
+}
+
+func (m Migrator) CurrentDatabase() (name string) {
+	_ = m.DB.Raw(
+		fmt.Sprintf(`SELECT SYS_CONTEXT('USERENV', 'DB_NAME') FROM %s`, m.Dialector.(Dialector).DummyTableName()),
+	).Row().Scan(&name)
+	return
+}
+
+func (m Migrator) GetTables() (tableList []string, err error) {
+	err = m.DB.Raw("SELECT TABLE_NAME FROM USER_TABLES").Scan(&tableList).Error
+	return
+}
+
+func (m Migrator) DropTable(values ...any) error {
+	values = m.ReorderModels(values, false)
+	for i := len(values) - 1; i >= 0; i-- {
+		value := values[i]
+		tx := m.DB.Session(&gorm.Session{})
+		if m.HasTable(value) {
+			if err := m.RunWithValue(value, func(stmt *gorm.Statement) error {
+				return tx.Exec("DROP TABLE ? CASCADE CONSTRAINTS PURGE", clause.Table{Name: stmt.Table}).Error
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m Migrator) HasTable(value any) bool {
+	var count int64
+	_ = m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		return m.DB.Raw("SELECT COUNT(*) FROM USER_TABLES WHERE TABLE_NAME = ?", strings.ToUpper(stmt.Table)).Row().Scan(&count)
+	})
+	return count > 0
+}
+
+func (m Migrator) RenameTable(oldName, newName any) (err error) {
+	resolveTable := func(name any) (result string, err error) {
+		if v, ok := name.(string); ok {
+			result = v
+		} else {
+			stmt := &gorm.Statement{DB: m.DB}
+			if err = stmt.Parse(name); err == nil {
+				result = stmt.Table
+			}
+		}
+		return
+	}
+
+	var oldTable, newTable string
+	if oldTable, err = resolveTable(oldName); err != nil {
+		return
+	}
+	if newTable, err = resolveTable(newName); err != nil {
+		return
+	}
+	return m.DB.Exec("ALTER TABLE ? RENAME TO ?",
+		clause.Table{Name: oldTable},
+		clause.Table{Name: newTable},
+	).Error
+}
+
+func (m Migrator) AlterColumn(value any, field string) error {
+	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		if field := stmt.Schema.LookUpField(field); field != nil {
+			return m.DB.Exec(
+				"ALTER TABLE ? MODIFY ? ?",
+				clause.Table{Name: stmt.Table},
+				clause.Column{Name: field.DBName},
+				m.FullDataTypeOf(field),
+			).Error
+		}
+		return fmt.Errorf("failed to look up field with name: %s", field)
+	})
+}
+
+func (m Migrator) HasColumn(value any, field string) bool {
+	var count int64
+	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		name := field
+		if stmt.Schema != nil {
+			if f := stmt.Schema.LookUpField(field); f != nil {
+				name = f.DBName
+			}
+		}
+		return m.DB.Raw(
+			"SELECT COUNT(*) FROM USER_TAB_COLUMNS WHERE TABLE_NAME = ? AND COLUMN_NAME = ?",
+			strings.ToUpper(stmt.Table), strings.ToUpper(name),
+		).Row().Scan(&count)
+	}) == nil && count > 0
+}
+
+func (m Migrator) HasConstraint(value any, name string) bool {
+	var count int64
+	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		return m.DB.Raw(
+			"SELECT COUNT(*) FROM USER_CONSTRAINTS WHERE TABLE_NAME = ? AND CONSTRAINT_NAME = ?",
+			strings.ToUpper(stmt.Table), strings.ToUpper(name),
+		).Row().Scan(&count)
+	}) == nil && count > 0
+}
+
+func (m Migrator) DropIndex(value any, name string) error {
+	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		if idx := stmt.Schema.LookIndex(name); idx != nil {
+			name = idx.Name
+		}
+		return m.DB.Exec("DROP INDEX ?", clause.Column{Name: name}).Error
+	})
+}
+
+func (m Migrator) HasIndex(value any, name string) bool {
+	var count int64
+	_ = m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		if idx := stmt.Schema.LookIndex(name); idx != nil {
+			name = idx.Name
+		}
+		return m.DB.Raw(
+			"SELECT COUNT(*) FROM USER_INDEXES WHERE TABLE_NAME = ? AND INDEX_NAME = ?",
+			strings.ToUpper(stmt.Table), strings.ToUpper(name),
+		).Row().Scan(&count)
+	})
+	return count > 0
+}
+
+func (m Migrator) RenameIndex(value any, oldName, newName string) error {
+	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		return m.DB.Exec(
+			"ALTER INDEX ? RENAME TO ?",
+			clause.Column{Name: oldName}, clause.Column{Name: newName},
+		).Error
+	})
+}
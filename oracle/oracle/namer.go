@@ -0,0 +1,40 @@
+package oracle
+
+import (
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// Namer 将表名、列名等转为大写
+type Namer struct {
+	schema.Namer
+}
+
+func (n Namer) TableName(table string) string {
+	return strings.ToUpper(n.Namer.TableName(table))
+}
+
+func (n Namer) ColumnName(table, column string) string {
+	return strings.ToUpper(n.Namer.ColumnName(table, column))
+}
+
+func (n Namer) JoinTableName(joinTable string) string {
+	return strings.ToUpper(n.Namer.JoinTableName(joinTable))
+}
+
+func (n Namer) RelationshipFKName(rel schema.Relationship) string {
+	return strings.ToUpper(n.Namer.RelationshipFKName(rel))
+}
+
+func (n Namer) CheckerName(table, column string) string {
+	return strings.ToUpper(n.Namer.CheckerName(table, column))
+}
+
+func (n Namer) IndexName(table, column string) string {
+	return strings.ToUpper(n.Namer.IndexName(table, column))
+}
+
+func (n Namer) UniqueName(table, column string) string {
+	return strings.ToUpper(n.Namer.UniqueName(table, column))
+}
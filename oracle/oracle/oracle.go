@@ -0,0 +1,233 @@
+// Package oracle 基于 go-ora 纯 Go 驱动的 gorm 方言，要求 Oracle 12c 及以上版本
+// 标识符统一转为大写并加引号，与 Oracle 未加引号标识符的大小写规则一致
+package oracle
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	_ "github.com/sijms/go-ora/v2"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
+)
+
+type Config struct {
+	Conn              *sql.DB
+	DriverName        string
+	DSN               string
+	DefaultStringSize uint
+}
+
+type Dialector struct {
+	*Config
+}
+
+func Open(dsn string) gorm.Dialector {
+	return &Dialector{Config: &Config{DSN: dsn}}
+}
+
+func New(config Config) gorm.Dialector {
+	return &Dialector{Config: &config}
+}
+
+func (d Dialector) DummyTableName() string {
+	return "DUAL"
+}
+
+func (d Dialector) Name() string {
+	return "oracle"
+}
+
+// Apply 将命名策略包装为大写，查询结果的列名与模型字段才能对应
+func (d Dialector) Apply(config *gorm.Config) error {
+	if config.NamingStrategy == nil {
+		config.NamingStrategy = schema.NamingStrategy{}
+	}
+	if _, ok := config.NamingStrategy.(Namer); !ok {
+		config.NamingStrategy = Namer{Namer: config.NamingStrategy}
+	}
+	return nil
+}
+
+func (d Dialector) Initialize(db *gorm.DB) (err error) {
+	if d.DefaultStringSize == 0 {
+		d.DefaultStringSize = 1024
+	}
+	if len(d.DriverName) == 0 {
+		d.DriverName = "oracle"
+	}
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+	if d.Conn != nil {
+		db.ConnPool = d.Conn
+	} else {
+		db.ConnPool, err = sql.Open(d.DriverName, d.DSN)
+		if err != nil {
+			return
+		}
+	}
+	if err = db.Callback().Create().Replace("gorm:create", Create); err != nil {
+		return
+	}
+	for k, v := range d.ClauseBuilders() {
+		db.ClauseBuilders[k] = v
+	}
+	return
+}
+
+func (d Dialector) ClauseBuilders() map[string]clause.ClauseBuilder {
+	return map[string]clause.ClauseBuilder{
+		"LIMIT": d.RewriteLimit,
+	}
+}
+
+// RewriteLimit 使用 OFFSET ... FETCH NEXT 分页
+func (d Dialector) RewriteLimit(c clause.Clause, builder clause.Builder) {
+	if limit, ok := c.Expression.(clause.Limit); ok {
+		offset := limit.Offset
+		if offset > 0 {
+			_, _ = builder.WriteString("OFFSET ")
+			_, _ = builder.WriteString(strconv.Itoa(offset))
+			_, _ = builder.WriteString(" ROWS")
+		}
+		if limit.Limit != nil && *limit.Limit >= 0 {
+			if offset > 0 {
+				_ = builder.WriteByte(' ')
+			}
+			_, _ = builder.WriteString("FETCH NEXT ")
+			_, _ = builder.WriteString(strconv.Itoa(*limit.Limit))
+			_, _ = builder.WriteString(" ROWS ONLY")
+		}
+	}
+}
+
+func (d Dialector) DefaultValueOf(*schema.Field) clause.Expression {
+	return clause.Expr{SQL: "VALUES (DEFAULT)"}
+}
+
+func (d Dialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return Migrator{
+		Migrator: migrator.Migrator{
+			Config: migrator.Config{
+				DB:                          db,
+				Dialector:                   d,
+				CreateIndexAfterCreateTable: true,
+			},
+		},
+	}
+}
+
+func (d Dialector) BindVarTo(writer clause.Writer, stmt *gorm.Statement, v any) {
+	_, _ = writer.WriteString(":")
+	_, _ = writer.WriteString(strconv.Itoa(len(stmt.Vars)))
+}
+
+// QuoteTo 标识符转为大写后加双引号，按 . 分段处理
+func (d Dialector) QuoteTo(writer clause.Writer, str string) {
+	for i, part := range strings.Split(str, ".") {
+		if i > 0 {
+			_ = writer.WriteByte('.')
+		}
+		if part == "*" {
+			_ = writer.WriteByte('*')
+			continue
+		}
+		_ = writer.WriteByte('"')
+		_, _ = writer.WriteString(strings.ToUpper(strings.Trim(part, `"`)))
+		_ = writer.WriteByte('"')
+	}
+}
+
+var numericPlaceholder = regexp.MustCompile(`:(\d+)`)
+
+// Explain 布尔值按 0、1 输出，RETURNING INTO 的输出参数保留 :n 占位符
+func (d Dialector) Explain(query string, vars ...any) string {
+	var outs []string
+	for i, v := range vars {
+		switch v := v.(type) {
+		case bool:
+			vars[i] = 0
+			if v {
+				vars[i] = 1
+			}
+		case sql.Out:
+			outs = append(outs, strconv.Itoa(i+1))
+			vars[i] = "\x00" + outs[len(outs)-1]
+		}
+	}
+	s := logger.ExplainSQL(query, numericPlaceholder, `'`, vars...)
+	for _, n := range outs {
+		s = strings.Replace(s, "'\x00"+n+"'", ":"+n, 1)
+	}
+	return s
+}
+
+func (d Dialector) DataTypeOf(field *schema.Field) string {
+	var sqlType string
+	switch field.DataType {
+	case schema.Bool:
+		sqlType = "NUMBER(1)"
+	case schema.Int, schema.Uint:
+		switch {
+		case field.Size <= 8:
+			sqlType = "NUMBER(3)"
+		case field.Size <= 16:
+			sqlType = "NUMBER(5)"
+		case field.Size <= 32:
+			sqlType = "NUMBER(10)"
+		default:
+			sqlType = "NUMBER(19)"
+		}
+		if field.AutoIncrement {
+			sqlType += " GENERATED BY DEFAULT AS IDENTITY"
+		}
+	case schema.Float:
+		sqlType = "BINARY_DOUBLE"
+		if field.Size == 32 {
+			sqlType = "BINARY_FLOAT"
+		}
+		if field.Precision > 0 {
+			sqlType = fmt.Sprintf("NUMBER(%d, %d)", field.Precision, field.Scale)
+		}
+	case schema.String:
+		size := field.Size
+		if size == 0 {
+			size = int(d.DefaultStringSize)
+		}
+		if size > 4000 {
+			sqlType = "CLOB"
+		} else {
+			sqlType = fmt.Sprintf("VARCHAR2(%d CHAR)", size)
+		}
+	case schema.Time:
+		sqlType = "TIMESTAMP WITH TIME ZONE"
+	case schema.Bytes:
+		sqlType = "BLOB"
+	default:
+		sqlType = string(field.DataType)
+		if strings.EqualFold(sqlType, "text") {
+			sqlType = "CLOB"
+		}
+		if sqlType == "" {
+			panic(fmt.Sprintf("invalid sql type %s (%s) for oracle", field.FieldType.Name(), field.FieldType.String()))
+		}
+	}
+	return sqlType
+}
+
+func (d Dialector) SavePoint(tx *gorm.DB, name string) error {
+	tx.Exec("SAVEPOINT " + name)
+	return tx.Error
+}
+
+func (d Dialector) RollbackTo(tx *gorm.DB, name string) error {
+	tx.Exec("ROLLBACK TO SAVEPOINT " + name)
+	return tx.Error
+}
@@ -0,0 +1,82 @@
+package oracle
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/livexy/plugins/dbbase"
+	"github.com/livexy/plugins/dbbasetest"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type invoice struct {
+	ID        int64   `gorm:"primaryKey;autoIncrement"`
+	Code      string  `gorm:"size:32;uniqueIndex"`
+	Amount    float64 `gorm:"precision:10;scale:2"`
+	Note      string  `gorm:"type:text"`
+	Paid      bool
+	CreatedAt time.Time
+}
+
+// 插入回调与迁移生成的语句
+func TestDryRun(t *testing.T) {
+	r := dbbasetest.Open(t, dbbase.Dialect{}, Open("oracle://test@127.0.0.1:1521/test"))
+	want := dbbasetest.Snapshots{
+		"createTable": `CREATE TABLE "INVOICE" ("ID" NUMBER(19) GENERATED BY DEFAULT AS IDENTITY,"CODE" VARCHAR2(32 CHAR),"AMOUNT" NUMBER(10, 2),"NOTE" CLOB,"PAID" NUMBER(1),"CREATED_AT" TIMESTAMP WITH TIME ZONE,PRIMARY KEY ("ID"));
+CREATE UNIQUE INDEX "IDX_INVOICE_CODE" ON "INVOICE"("CODE")`,
+		"alterColumn":   `ALTER TABLE "INVOICE" MODIFY "NOTE" CLOB`,
+		"renameTable":   `ALTER TABLE "INVOICE" RENAME TO "BILL"`,
+		"renameIndex":   `ALTER INDEX "IDX_A" RENAME TO "IDX_B"`,
+		"dropIndex":     `DROP INDEX "IDX_INVOICE_CODE"`,
+		"create":        `INSERT INTO "INVOICE" ("CODE","AMOUNT","NOTE","PAID","CREATED_AT") VALUES ('a',1.5,'',1,'2024-01-02 03:04:05') RETURNING "ID" INTO :6`,
+		"createMap":     `INSERT INTO "INVOICE" ("CODE") VALUES ('b') RETURNING "ID" INTO :2`,
+		"createDefault": `INSERT INTO "INVOICE" ("ID") VALUES (DEFAULT) RETURNING "ID" INTO :1`,
+	}
+	tests := []struct {
+		name string
+		run  func(db *gorm.DB) error
+	}{
+		{"createTable", func(db *gorm.DB) error {
+			return db.Migrator().CreateTable(&invoice{})
+		}},
+		{"alterColumn", func(db *gorm.DB) error {
+			return db.Migrator().AlterColumn(&invoice{}, "Note")
+		}},
+		{"renameTable", func(db *gorm.DB) error {
+			return db.Migrator().RenameTable("invoice", "bill")
+		}},
+		{"renameIndex", func(db *gorm.DB) error {
+			return db.Migrator().RenameIndex(&invoice{}, "idx_a", "idx_b")
+		}},
+		{"dropIndex", func(db *gorm.DB) error {
+			return db.Migrator().DropIndex(&invoice{}, "Code")
+		}},
+		{"create", func(db *gorm.DB) error {
+			return db.Create(&invoice{Code: "a", Amount: 1.5, Paid: true, CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}).Error
+		}},
+		{"createMap", func(db *gorm.DB) error {
+			return db.Table("invoice").Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).Create(map[string]any{"code": "b"}).Error
+		}},
+		{"createDefault", func(db *gorm.DB) error {
+			return db.Omit("Code", "Amount", "Note", "Paid", "CreatedAt").Create(&invoice{}).Error
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(r.DB.DB()); err != nil {
+				t.Fatal(err)
+			}
+			dbbasetest.Check(t, want, tt.name, r.Take())
+		})
+	}
+	t.Run("onConflict", func(t *testing.T) {
+		err := r.DB.DB().Clauses(clause.OnConflict{UpdateAll: true}).Create(&invoice{Code: "c"}).Error
+		if !errors.Is(err, ErrOnConflict) {
+			t.Fatalf("err = %v", err)
+		}
+		r.Take()
+	})
+}
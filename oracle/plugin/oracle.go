@@ -0,0 +1,57 @@
+package plugin
+
+import (
+	"github.com/livexy/plugin/dber"
+	"github.com/livexy/plugins/dbbase"
+	"github.com/livexy/plugins/oracle/oracle"
+
+	go_ora "github.com/sijms/go-ora/v2"
+)
+
+// Oracle 方言，要求 12c 及以上版本
+// 插入回调通过 RETURNING ... INTO 回填主键，不需要额外查询自增 ID
+var dialect = dbbase.Dialect{
+	Name:  "oracle",
+	Open:  oracle.Open,
+	Slots: 65535,
+
+	// 空值判断使用 nvl；Oracle 没有 if(cond, a, b) 函数，If() 返回空字符串，
+	// 条件表达式请使用 Helper().Cond 生成 CASE WHEN
+	IfNull: "nvl",
+	If:     "",
+	GroupConcat: func(field string) string {
+		return "listagg(" + field + ", ',') within group (order by " + field + ")"
+	},
+	Helper:   dbbase.OracleHelper{},
+	ClobScan: clobScan,
+
+	IDMode: dbbase.IDReturning,
+
+	Upsert:    dbbase.UpsertMerge,
+	DualTable: "DUAL",
+}
+
+// New 创建一个新的 Oracle 数据库适配实例
+// If() 返回空字符串，不能拼接 If()+"(c, a, b)"，条件表达式须使用 Helper().Cond
+func New() dber.Dber {
+	return dbbase.New(dialect)
+}
+
+// 处理 Oracle 数据库的 CLOB 类型扫描与转换
+func clobScan(clob *dber.Clob, v any) error {
+	switch val := v.(type) {
+	case go_ora.Clob:
+		*clob = dber.Clob(val.String)
+	case *go_ora.Clob:
+		*clob = dber.Clob(val.String)
+	case go_ora.NClob:
+		*clob = dber.Clob(val.String)
+	case *go_ora.NClob:
+		*clob = dber.Clob(val.String)
+	case []uint8:
+		*clob = dber.Clob(string(val))
+	case string:
+		*clob = dber.Clob(val)
+	}
+	return nil
+}
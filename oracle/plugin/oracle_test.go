@@ -0,0 +1,34 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/livexy/plugins/dbbasetest"
+)
+
+func TestDryRun(t *testing.T) {
+	dbbasetest.Run(t, dbbasetest.Open(t, dialect, dialect.Open("oracle://test@127.0.0.1:1521/test")), dbbasetest.Suite{
+		Upsert: dbbasetest.Snapshots{
+			"model":         `MERGE INTO "STOCK" USING (SELECT 'a' AS "NAME", 2 AS "QTY", 1 AS "ID" FROM DUAL) "EXCLUDED" ON ("STOCK"."ID" = "EXCLUDED"."ID") WHEN MATCHED THEN UPDATE SET "NAME" = "EXCLUDED"."NAME", "QTY" = "EXCLUDED"."QTY" WHEN NOT MATCHED THEN INSERT ("NAME", "QTY", "ID") VALUES ("EXCLUDED"."NAME", "EXCLUDED"."QTY", "EXCLUDED"."ID")`,
+			"autoIncrement": `error: upsert 需要指定冲突列：插入列中没有冲突列 ID，自增主键为零时请指定其他冲突列`,
+			"updates":       `MERGE INTO "STOCK" USING (SELECT 1 AS "ID", 'a' AS "NAME", 2 AS "QTY" FROM DUAL UNION ALL SELECT 2 AS "ID", 'b' AS "NAME", 3 AS "QTY" FROM DUAL) "EXCLUDED" ON ("STOCK"."ID" = "EXCLUDED"."ID") WHEN MATCHED THEN UPDATE SET "NAME" = "EXCLUDED"."NAME" WHEN NOT MATCHED THEN INSERT ("ID", "NAME", "QTY") VALUES ("EXCLUDED"."ID", "EXCLUDED"."NAME", "EXCLUDED"."QTY")`,
+			"increments":    `MERGE INTO "STOCK" USING (SELECT 1 AS "ID", 'a' AS "NAME", 2 AS "QTY" FROM DUAL UNION ALL SELECT 2 AS "ID", 'b' AS "NAME", 3 AS "QTY" FROM DUAL) "EXCLUDED" ON ("STOCK"."ID" = "EXCLUDED"."ID") WHEN MATCHED THEN UPDATE SET "QTY" = "STOCK"."QTY" + 1 WHEN NOT MATCHED THEN INSERT ("ID", "NAME", "QTY") VALUES ("EXCLUDED"."ID", "EXCLUDED"."NAME", "EXCLUDED"."QTY")`,
+			"doNothing":     `MERGE INTO "STOCK" USING (SELECT 1 AS "ID", 'a' AS "NAME", 2 AS "QTY" FROM DUAL UNION ALL SELECT 2 AS "ID", 'b' AS "NAME", 3 AS "QTY" FROM DUAL) "EXCLUDED" ON ("STOCK"."ID" = "EXCLUDED"."ID") WHEN NOT MATCHED THEN INSERT ("ID", "NAME", "QTY") VALUES ("EXCLUDED"."ID", "EXCLUDED"."NAME", "EXCLUDED"."QTY")`,
+		},
+		Helper: dbbasetest.Snapshots{
+			"date":      `SELECT TRUNC(created_at, 'DD'), CAST(created_at AS TIMESTAMP(0)), TO_CHAR(created_at, 'YYYY-MM-DD HH24:MI:SS'), ADD_MONTHS(created_at, -1), (created_at + NUMTODSINTERVAL(2, 'HOUR')), TRUNC((CAST(updated_at AS DATE) - CAST(created_at AS DATE)) * 1), TRUNC(MONTHS_BETWEEN(updated_at, created_at)) FROM event`,
+			"string":    `SELECT (first_name || ' ' || last_name), SUBSTR(name, 2, 3), SUBSTR(name, 2) FROM person WHERE LOWER(name) LIKE LOWER('%it''s%')`,
+			"json":      `SELECT JSON_VALUE(data, '$.user.name') FROM event WHERE active = 1`,
+			"aggregate": `SELECT dept, LISTAGG(name, '; ') WITHIN GROUP (ORDER BY name) FROM person GROUP BY dept`,
+			"paging": `SELECT id FROM event ORDER BY DBMS_RANDOM.VALUE OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY;
+SELECT id FROM event ORDER BY id OFFSET 5 ROWS`,
+		},
+		CRUD: dbbasetest.Snapshots{
+			"create": `INSERT INTO "STOCK" ("NAME","QTY") VALUES ('a',1) RETURNING "ID" INTO :3`,
+			"find":   `SELECT * FROM "STOCK" WHERE qty > 1 ORDER BY id OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY`,
+			"update": `UPDATE "STOCK" SET "NAME"='b',"QTY"=qty + 1 WHERE "ID" = 1`,
+			"delete": `DELETE FROM "STOCK" WHERE "STOCK"."ID" = 1`,
+			"cond":   `SELECT id, nvl(name, '') AS name, CASE WHEN qty > 10 THEN 'many' ELSE 'few' END AS level FROM "STOCK"`,
+		},
+	})
+}
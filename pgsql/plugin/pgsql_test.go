@@ -0,0 +1,34 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/livexy/plugins/dbbasetest"
+)
+
+func TestDryRun(t *testing.T) {
+	dbbasetest.Run(t, dbbasetest.Open(t, dialect, dialect.Open("host=127.0.0.1 user=test dbname=test")), dbbasetest.Suite{
+		Upsert: dbbasetest.Snapshots{
+			"model":         `INSERT INTO "stock" ("name", "qty", "id") VALUES ('a',2,1) ON CONFLICT ("id") DO UPDATE SET "name" = "excluded"."name", "qty" = "excluded"."qty"`,
+			"autoIncrement": `INSERT INTO "stock" ("name", "qty") VALUES ('a',2) ON CONFLICT ("id") DO UPDATE SET "name" = "excluded"."name", "qty" = "excluded"."qty"`,
+			"updates":       `INSERT INTO "stock" ("id", "name", "qty") VALUES (1,'a',2), (2,'b',3) ON CONFLICT ("id") DO UPDATE SET "name" = "excluded"."name"`,
+			"increments":    `INSERT INTO "stock" ("id", "name", "qty") VALUES (1,'a',2), (2,'b',3) ON CONFLICT ("id") DO UPDATE SET "qty" = "stock"."qty" + 1`,
+			"doNothing":     `INSERT INTO "stock" ("id", "name", "qty") VALUES (1,'a',2), (2,'b',3) ON CONFLICT ("id") DO NOTHING`,
+		},
+		Helper: dbbasetest.Snapshots{
+			"date":      `SELECT date_trunc('day', created_at), date_trunc('second', created_at), to_char(created_at, 'YYYY-MM-DD HH24:MI:SS'), (created_at + interval '-1 month'), (created_at + interval '2 hour'), cast(trunc(extract(epoch from (updated_at) - (created_at)) / 86400) as bigint), cast(date_part('year', age(updated_at, created_at)) * 12 + date_part('month', age(updated_at, created_at)) as bigint) FROM event`,
+			"string":    `SELECT concat(first_name, ' ', last_name), substr(name, 2, 3), substr(name, 2) FROM person WHERE name ILIKE '%it''s%'`,
+			"json":      `SELECT cast(data as jsonb) #>> '{user,name}' FROM event WHERE active = true`,
+			"aggregate": `SELECT dept, string_agg(cast(name as text), '; ' ORDER BY name) FROM person GROUP BY dept`,
+			"paging": `SELECT id FROM event ORDER BY random() LIMIT 10 OFFSET 20;
+SELECT id FROM event ORDER BY id LIMIT ALL OFFSET 5`,
+		},
+		CRUD: dbbasetest.Snapshots{
+			"create": `INSERT INTO "stock" ("name","qty") VALUES ('a',1) RETURNING "id"`,
+			"find":   `SELECT * FROM "stock" WHERE qty > 1 ORDER BY id LIMIT 10 OFFSET 20`,
+			"update": `UPDATE "stock" SET "name"='b',"qty"=qty + 1 WHERE "id" = 1`,
+			"delete": `DELETE FROM "stock" WHERE "stock"."id" = 1`,
+			"cond":   `SELECT id, coalesce(name, '') AS name, CASE WHEN qty > 10 THEN 'many' ELSE 'few' END AS level FROM "stock"`,
+		},
+	})
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"github.com/livexy/plugin/cacher"
 	plug "github.com/livexy/plugins/redis/plugin"
+	"github.com/livexy/plugins/rediser"
 
 	"go.uber.org/zap"
 )
@@ -13,3 +14,7 @@ type plugin struct{}
 func(p plugin) New(cfg cacher.CacheConfig, logger *zap.Logger) (cacher.Cacher, error) {
 	return plug.NewRedisCache(cfg, logger)
 }
+
+func(p plugin) NewWithConfig(cfg rediser.RedisConfig, logger *zap.Logger) (rediser.RedisCacher, error) {
+	return plug.NewRedisCacheWithConfig(cfg, logger)
+}
@@ -0,0 +1,331 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/livexy/plugins/rediser"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrTxFailed WATCH 的键在事务提交前被修改，事务未执行
+var ErrTxFailed = rediser.ErrTxFailed
+
+// BatchResult 批量命令的单条结果，顺序与入队顺序一致
+type BatchResult = rediser.BatchResult
+
+// Batch 批量命令构建器，键自动加前缀
+// 集群模式下 Pipeline 按节点拆分发送；TxPipeline 的所有键必须位于同一槽，
+// 跨槽时整批返回 CROSSSLOT 错误，应使用 hash tag 让相关键落在同一槽
+type Batch = rediser.Batch
+
+var _ Batch = (*redisBatch)(nil)
+
+type redisBatch struct {
+	cache  *redisCache
+	pipe   redis.Pipeliner
+	ctx    context.Context // 读取命名空间代数
+	prefix string          // 首条命令入队时确定，同批命令使用同一代数
+	err    error           // 读取代数失败时 Exec 直接返回，不发送任何命令
+	keys   []string        // 各命令对应的原始键
+	ckeys  []string        // 写命令涉及的完整键，执行后失效一级缓存
+}
+
+// Pipeline 创建批量命令，一次往返发送，不保证原子性
+func (cache *redisCache) Pipeline() Batch {
+	return &redisBatch{cache: cache, pipe: cache.rdb.Pipeline(), ctx: ctx}
+}
+
+// TxPipeline 创建 MULTI/EXEC 事务批量命令
+func (cache *redisCache) TxPipeline() Batch {
+	return &redisBatch{cache: cache, pipe: cache.rdb.TxPipeline(), ctx: ctx}
+}
+
+// Len 已入队命令数
+func (b *redisBatch) Len() int {
+	return b.pipe.Len()
+}
+
+// 记录读命令
+func (b *redisBatch) read(key string) string {
+	b.keys = append(b.keys, key)
+	if len(b.keys) == 1 {
+		b.prefix, b.err = b.cache.keyPrefix(b.ctx)
+	}
+	return b.prefix + ":" + key
+}
+
+// 记录写命令
+func (b *redisBatch) write(key string) string {
+	ckey := b.read(key)
+	b.ckeys = append(b.ckeys, ckey)
+	return ckey
+}
+
+// Exec 执行并返回每条命令的结果，error 为第一条失败命令的错误（不含键不存在）
+func (b *redisBatch) Exec(ctx context.Context) ([]BatchResult, error) {
+	if b.err != nil {
+		err := b.err
+		b.pipe.Discard()
+		b.keys, b.ckeys, b.err = nil, nil, nil
+		return nil, err
+	}
+	cmds, err := b.pipe.Exec(ctx)
+	// Exec 只返回第一条失败命令的错误，为键不存在时继续查找之后的命令
+	if errors.Is(err, redis.Nil) {
+		err = nil
+		for _, cmd := range cmds {
+			if cerr := cmd.Err(); cerr != nil && !errors.Is(cerr, redis.Nil) {
+				err = cerr
+				break
+			}
+		}
+	}
+	if len(b.ckeys) > 0 && !errors.Is(err, ErrTxFailed) {
+		b.cache.invalidate(ctx, b.ckeys...)
+	}
+	results := make([]BatchResult, 0, len(cmds))
+	for i, cmd := range cmds {
+		result := BatchResult{Name: cmd.Name(), Val: cmdVal(cmd), Err: notFound(cmd.Err())}
+		if i < len(b.keys) {
+			result.Key = b.keys[i]
+		}
+		results = append(results, result)
+	}
+	b.keys, b.ckeys = nil, nil
+	return results, err
+}
+
+// 取命令返回值
+func cmdVal(cmd redis.Cmder) any {
+	switch c := cmd.(type) {
+	case *redis.StatusCmd:
+		return c.Val()
+	case *redis.StringCmd:
+		return c.Val()
+	case *redis.IntCmd:
+		return c.Val()
+	case *redis.BoolCmd:
+		return c.Val()
+	case *redis.FloatCmd:
+		return c.Val()
+	case *redis.SliceCmd:
+		return c.Val()
+	case *redis.StringSliceCmd:
+		return c.Val()
+	case *redis.MapStringStringCmd:
+		return c.Val()
+	case *redis.Cmd:
+		return c.Val()
+	}
+	return nil
+}
+
+func (b *redisBatch) Set(key string, value any, expiration time.Duration) Batch {
+	b.pipe.Set(ctx, b.write(key), value, expiration)
+	return b
+}
+func (b *redisBatch) SetNX(key string, value any, expiration time.Duration) Batch {
+	b.pipe.SetNX(ctx, b.write(key), value, expiration)
+	return b
+}
+func (b *redisBatch) SetXX(key string, value any, expiration time.Duration) Batch {
+	b.pipe.SetXX(ctx, b.write(key), value, expiration)
+	return b
+}
+func (b *redisBatch) Incr(key string) Batch {
+	b.pipe.Incr(ctx, b.write(key))
+	return b
+}
+func (b *redisBatch) IncrBy(key string, val int64) Batch {
+	b.pipe.IncrBy(ctx, b.write(key), val)
+	return b
+}
+func (b *redisBatch) Decr(key string) Batch {
+	b.pipe.Decr(ctx, b.write(key))
+	return b
+}
+func (b *redisBatch) DecrBy(key string, val int64) Batch {
+	b.pipe.DecrBy(ctx, b.write(key), val)
+	return b
+}
+func (b *redisBatch) Exists(key string) Batch {
+	b.pipe.Exists(ctx, b.read(key))
+	return b
+}
+func (b *redisBatch) Get(key string) Batch {
+	b.pipe.Get(ctx, b.read(key))
+	return b
+}
+func (b *redisBatch) GetSet(key string, value any) Batch {
+	b.pipe.GetSet(ctx, b.write(key), value)
+	return b
+}
+func (b *redisBatch) Delete(key string) Batch {
+	b.pipe.Del(ctx, b.write(key))
+	return b
+}
+func (b *redisBatch) Unlink(key string) Batch {
+	b.pipe.Unlink(ctx, b.write(key))
+	return b
+}
+
+func (b *redisBatch) HExists(key, field string) Batch {
+	b.pipe.HExists(ctx, b.read(key), field)
+	return b
+}
+func (b *redisBatch) HGet(key, field string) Batch {
+	b.pipe.HGet(ctx, b.read(key), field)
+	return b
+}
+func (b *redisBatch) HGetAll(key string) Batch {
+	b.pipe.HGetAll(ctx, b.read(key))
+	return b
+}
+func (b *redisBatch) HKeys(key string) Batch {
+	b.pipe.HKeys(ctx, b.read(key))
+	return b
+}
+func (b *redisBatch) HLen(key string) Batch {
+	b.pipe.HLen(ctx, b.read(key))
+	return b
+}
+func (b *redisBatch) HSet(key string, values ...any) Batch {
+	b.pipe.HSet(ctx, b.write(key), values...)
+	return b
+}
+func (b *redisBatch) HIncrBy(key, field string, incr int64) Batch {
+	b.pipe.HIncrBy(ctx, b.write(key), field, incr)
+	return b
+}
+func (b *redisBatch) HDel(key string, fields ...string) Batch {
+	b.pipe.HDel(ctx, b.write(key), fields...)
+	return b
+}
+
+func (b *redisBatch) Expire(key string, expiration time.Duration) Batch {
+	b.pipe.Expire(ctx, b.write(key), expiration)
+	return b
+}
+func (b *redisBatch) PExpire(key string, expiration time.Duration) Batch {
+	b.pipe.PExpire(ctx, b.write(key), expiration)
+	return b
+}
+func (b *redisBatch) ExpireAt(key string, tm time.Time) Batch {
+	b.pipe.ExpireAt(ctx, b.write(key), tm)
+	return b
+}
+func (b *redisBatch) PExpireAt(key string, tm time.Time) Batch {
+	b.pipe.PExpireAt(ctx, b.write(key), tm)
+	return b
+}
+
+func (b *redisBatch) GetBit(key string, offset int64) Batch {
+	b.pipe.GetBit(ctx, b.read(key), offset)
+	return b
+}
+func (b *redisBatch) SetBit(key string, offset int64, val int) Batch {
+	b.pipe.SetBit(ctx, b.write(key), offset, val)
+	return b
+}
+func (b *redisBatch) BitCount(key string, start, end int64) Batch {
+	b.pipe.BitCount(ctx, b.read(key), &redis.BitCount{Start: start, End: end})
+	return b
+}
+
+func (b *redisBatch) LLen(key string) Batch {
+	b.pipe.LLen(ctx, b.read(key))
+	return b
+}
+func (b *redisBatch) LPush(key string, values ...any) Batch {
+	b.pipe.LPush(ctx, b.write(key), values...)
+	return b
+}
+func (b *redisBatch) LPop(key string) Batch {
+	b.pipe.LPop(ctx, b.write(key))
+	return b
+}
+func (b *redisBatch) RPush(key string, values ...any) Batch {
+	b.pipe.RPush(ctx, b.write(key), values...)
+	return b
+}
+func (b *redisBatch) RPop(key string) Batch {
+	b.pipe.RPop(ctx, b.write(key))
+	return b
+}
+func (b *redisBatch) LRange(key string, start, stop int64) Batch {
+	b.pipe.LRange(ctx, b.read(key), start, stop)
+	return b
+}
+
+// Tx WATCH 乐观事务，在 fn 中读取被监视的键，再通过 Exec 提交写命令
+type Tx = rediser.Tx
+
+var _ Tx = (*redisTx)(nil)
+
+type redisTx struct {
+	cache *redisCache
+	tx    *redis.Tx
+}
+
+// Watch 监视键并执行 fn，被监视的键在提交前被修改时返回 ErrTxFailed，由调用方决定是否重试
+// 集群模式下所有被监视的键必须位于同一槽
+func (cache *redisCache) Watch(ctx context.Context, fn func(tx Tx) error, keys ...string) error {
+	ckeys, err := cache.getKeys(ctx, keys)
+	if err != nil {
+		return err
+	}
+	return cache.rdb.Watch(ctx, func(tx *redis.Tx) error {
+		return fn(&redisTx{cache: cache, tx: tx})
+	}, ckeys...)
+}
+
+// Get 在事务连接上读取数据，键不存在时返回 false
+func (t *redisTx) Get(ctx context.Context, key string) (string, bool, error) {
+	ckey, err := t.cache.getKey(ctx, key)
+	if err != nil {
+		return "", false, err
+	}
+	val, err := t.tx.Get(ctx, ckey).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return val, true, nil
+}
+
+// HGet 在事务连接上读取字段，字段不存在时返回 false
+func (t *redisTx) HGet(ctx context.Context, key, field string) (string, bool, error) {
+	ckey, err := t.cache.getKey(ctx, key)
+	if err != nil {
+		return "", false, err
+	}
+	val, err := t.tx.HGet(ctx, ckey, field).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return val, true, nil
+}
+
+// HGetAll 在事务连接上读取整个哈希
+func (t *redisTx) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	ckey, err := t.cache.getKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return t.tx.HGetAll(ctx, ckey).Result()
+}
+
+// Exec 以 MULTI/EXEC 提交 fn 中入队的命令
+func (t *redisTx) Exec(ctx context.Context, fn func(b Batch)) ([]BatchResult, error) {
+	b := &redisBatch{cache: t.cache, pipe: t.tx.TxPipeline(), ctx: ctx}
+	fn(b)
+	return b.Exec(ctx)
+}
@@ -0,0 +1,128 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/livexy/plugin/cacher"
+
+	"go.uber.org/zap"
+)
+
+func TestPipeline(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+	cache.Set("s", "abc", 0)
+
+	results, err := cache.Pipeline().Set("a", "1", 0).Incr("n").Get("missing").Get("a").Exec(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 4 || results[1].Val != int64(1) || results[3].Val != "1" || !errors.Is(results[2].Err, ErrNotFound) {
+		t.Fatalf("results = %+v", results)
+	}
+
+	// 键不存在排在前面时，之后命令的真实错误不能被吞掉
+	results, err = cache.Pipeline().Get("missing").Incr("s").Exec(ctx)
+	if err == nil || !strings.Contains(err.Error(), "not an integer") {
+		t.Fatalf("err = %v", err)
+	}
+	if !errors.Is(results[0].Err, ErrNotFound) || results[1].Err == nil {
+		t.Fatalf("results = %+v", results)
+	}
+}
+
+func TestTxPipeline(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+
+	results, err := cache.TxPipeline().Set("a", "1", 0).IncrBy("a", 2).Exec(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[1].Val != int64(3) || results[1].Key != "a" {
+		t.Fatalf("results = %+v", results)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+	cache.Set("balance", "10", 0)
+
+	err := cache.Watch(ctx, func(tx Tx) error {
+		val, ok, err := tx.Get(ctx, "balance")
+		if err != nil || !ok || val != "10" {
+			t.Fatalf("get = %q, %v, %v", val, ok, err)
+		}
+		_, err = tx.Exec(ctx, func(b Batch) { b.DecrBy("balance", 3) })
+		return err
+	}, "balance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := cache.Get("balance"); v != "7" {
+		t.Fatalf("balance = %q", v)
+	}
+
+	// 提交前被其他客户端修改
+	err = cache.Watch(ctx, func(tx Tx) error {
+		if _, _, err := tx.Get(ctx, "balance"); err != nil {
+			return err
+		}
+		if !cache.Set("balance", "100", 0) {
+			return errors.New("set balance failed")
+		}
+		_, err := tx.Exec(ctx, func(b Batch) { b.DecrBy("balance", 3) })
+		return err
+	}, "balance")
+	if !errors.Is(err, ErrTxFailed) {
+		t.Fatalf("err = %v", err)
+	}
+	if v := cache.Get("balance"); v != "100" {
+		t.Fatalf("balance = %q", v)
+	}
+}
+
+// 集群模式下 Pipeline 可跨槽，TxPipeline 只能在同一槽内
+func TestTxPipelineCluster(t *testing.T) {
+	_, mr := newTestCache(t)
+	c, err := NewRedisCacheWithConfig(RedisConfig{CacheConfig: cacher.CacheConfig{
+		Addr: []string{"redis://" + mr.Addr() + "?addr=" + mr.Addr()}, Prefix: "test",
+	}}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	cache := c.(*redisCache)
+	ctx := context.Background()
+
+	results, err := cache.Pipeline().Set("{a}1", "1", 0).Set("{b}1", "2", 0).Get("{b}1").Exec(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || results[2].Val != "2" || results[2].Key != "{b}1" {
+		t.Fatalf("results = %+v", results)
+	}
+
+	results, err = cache.TxPipeline().Set("{a}1", "3", 0).Incr("{a}2").Exec(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[1].Val != int64(1) {
+		t.Fatalf("results = %+v", results)
+	}
+	if v := cache.Get("{a}1"); v != "3" {
+		t.Fatalf("{a}1 = %q", v)
+	}
+
+	_, err = cache.TxPipeline().Set("{a}1", "4", 0).Set("{b}1", "4", 0).Exec(ctx)
+	if err == nil || !strings.Contains(err.Error(), "CROSSSLOT") {
+		t.Fatalf("err = %v", err)
+	}
+	if v := cache.Get("{a}1"); v != "3" {
+		t.Fatalf("{a}1 = %q", v)
+	}
+}
@@ -0,0 +1,163 @@
+package plugin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"time"
+
+	"github.com/livexy/plugins/rediser"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis 位图最大 2^32 位
+const bloomMaxBits = 1 << 32
+
+// 添加元素 返回每个元素此前是否不存在
+// KEYS[1] 位图 ARGV[1] 哈希数 ARGV[2] 过期毫秒，0 不设置 ARGV[3..] 每个元素的位偏移
+var bloomAddScript = redis.NewScript(`
+local k = tonumber(ARGV[1])
+local ttl = tonumber(ARGV[2])
+local added = {}
+local n = (#ARGV - 2) / k
+for i = 0, n - 1 do
+	local fresh = 0
+	for j = 1, k do
+		if redis.call('setbit', KEYS[1], ARGV[2 + i * k + j], 1) == 0 then
+			fresh = 1
+		end
+	end
+	added[i + 1] = fresh
+end
+if ttl > 0 and redis.call('pttl', KEYS[1]) < 0 then
+	redis.call('pexpire', KEYS[1], ttl)
+end
+return added
+`)
+
+// 判断元素是否可能存在
+// KEYS[1] 位图 ARGV[1] 哈希数 ARGV[2..] 每个元素的位偏移
+var bloomExistsScript = redis.NewScript(`
+local k = tonumber(ARGV[1])
+local found = {}
+local n = (#ARGV - 1) / k
+for i = 0, n - 1 do
+	local hit = 1
+	for j = 1, k do
+		if redis.call('getbit', KEYS[1], ARGV[1 + i * k + j]) == 0 then
+			hit = 0
+			break
+		end
+	end
+	found[i + 1] = hit
+end
+return found
+`)
+
+// BloomOptions 布隆过滤器选项
+type BloomOptions = rediser.BloomOptions
+
+// Bloom 基于位图的布隆过滤器，整个过滤器位于单个键，集群下安全
+// 同名过滤器的容量与误判率须保持一致，否则位偏移不同会导致误判
+type Bloom = rediser.Bloom
+
+var _ Bloom = (*redisBloom)(nil)
+
+type redisBloom struct {
+	cache  *redisCache
+	name   string
+	bits   uint64
+	hashes int
+	ttl    time.Duration
+}
+
+// NewBloom 创建布隆过滤器，按容量与误判率计算位数和哈希数
+func (cache *redisCache) NewBloom(name string, opt *BloomOptions) Bloom {
+	o := BloomOptions{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.Capacity <= 0 {
+		o.Capacity = 1000000
+	}
+	if o.ErrorRate <= 0 || o.ErrorRate >= 1 {
+		o.ErrorRate = 0.01
+	}
+	m := math.Ceil(-float64(o.Capacity) * math.Log(o.ErrorRate) / (math.Ln2 * math.Ln2))
+	m = min(max(m, 8), bloomMaxBits)
+	k := int(math.Round(m / float64(o.Capacity) * math.Ln2))
+	return &redisBloom{
+		cache: cache, name: name,
+		bits: uint64(m), hashes: min(max(k, 1), 30), ttl: o.TTL,
+	}
+}
+
+// 完整键名，命名空间视图的代数可能变化，每次调用时重新计算
+func (b *redisBloom) key(ctx context.Context) (string, error) {
+	return b.cache.getKey(ctx, "Bloom:"+b.name)
+}
+
+// 双重哈希计算每个元素的位偏移，两个哈希取自 SHA-256 互不相关的两段
+func (b *redisBloom) offsets(args []any, items []string) []any {
+	for _, item := range items {
+		sum := sha256.Sum256([]byte(item))
+		x, y := binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:16])|1
+		for i := range b.hashes {
+			args = append(args, (x+uint64(i)*y)%b.bits)
+		}
+	}
+	return args
+}
+
+// Add 添加元素，返回每个元素此前是否不存在（可能误判为已存在）
+func (b *redisBloom) Add(ctx context.Context, items ...string) ([]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	key, err := b.key(ctx)
+	if err != nil {
+		return nil, err
+	}
+	args := b.offsets([]any{b.hashes, b.ttl.Milliseconds()}, items)
+	vals, err := bloomAddScript.Run(ctx, b.cache.rdb, []string{key}, args...).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	return toBools(vals), nil
+}
+
+// Exists 判断元素是否可能存在，返回 false 时一定不存在
+func (b *redisBloom) Exists(ctx context.Context, items ...string) ([]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	key, err := b.key(ctx)
+	if err != nil {
+		return nil, err
+	}
+	args := b.offsets([]any{b.hashes}, items)
+	vals, err := bloomExistsScript.Run(ctx, b.cache.rdb, []string{key}, args...).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	return toBools(vals), nil
+}
+
+// Reset 清空过滤器
+func (b *redisBloom) Reset(ctx context.Context) error {
+	key, err := b.key(ctx)
+	if err != nil {
+		return err
+	}
+	return b.cache.DeleteKeysCtx(ctx, key)
+}
+
+func toBools(vals []int64) []bool {
+	list := make([]bool, 0, len(vals))
+	for _, v := range vals {
+		list = append(list, v == 1)
+	}
+	return list
+}
@@ -0,0 +1,66 @@
+package plugin
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestBloom(t *testing.T) {
+	cache, mr := newTestCache(t)
+	ctx := context.Background()
+	bloom := cache.NewBloom("b", &BloomOptions{Capacity: 100, ErrorRate: 0.01, TTL: time.Hour})
+
+	added, err := bloom.Add(ctx, "a", "b", "a")
+	if err != nil || !slices.Equal(added, []bool{true, true, false}) {
+		t.Fatalf("add = %v, %v", added, err)
+	}
+	found, err := bloom.Exists(ctx, "a", "b", "c")
+	if err != nil || !slices.Equal(found, []bool{true, true, false}) {
+		t.Fatalf("exists = %v, %v", found, err)
+	}
+	key := cache.stableKey("Bloom:b")
+	if ttl := mr.TTL(key); ttl != time.Hour {
+		t.Fatalf("ttl = %v", ttl)
+	}
+	if err = bloom.Reset(ctx); err != nil || mr.Exists(key) {
+		t.Fatalf("reset = %v", err)
+	}
+	if found, err = bloom.Exists(ctx, "a"); err != nil || found[0] {
+		t.Fatalf("exists after reset = %v, %v", found, err)
+	}
+}
+
+// 按容量写满后误判率应接近设定值
+func TestBloomErrorRate(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+	const capacity, rate = 1000, 0.01
+	bloom := cache.NewBloom("rate", &BloomOptions{Capacity: capacity, ErrorRate: rate})
+	items := func(prefix string, n int) []string {
+		list := make([]string, 0, n)
+		for i := range n {
+			list = append(list, prefix+strconv.Itoa(i))
+		}
+		return list
+	}
+	if _, err := bloom.Add(ctx, items("in:", capacity)...); err != nil {
+		t.Fatal(err)
+	}
+	const probes = 10000
+	found, err := bloom.Exists(ctx, items("out:", probes)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fp int
+	for _, ok := range found {
+		if ok {
+			fp++
+		}
+	}
+	if got := float64(fp) / probes; got > rate*1.5 {
+		t.Fatalf("false positive rate = %.4f, want about %.2f", got, rate)
+	}
+}
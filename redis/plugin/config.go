@@ -0,0 +1,122 @@
+package plugin
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/livexy/plugins/rediser"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisConfig 扩展的 Redis 配置，定义在 rediser 包
+type RedisConfig = rediser.RedisConfig
+
+// TLSConfig TLS 连接配置
+type TLSConfig = rediser.TLSConfig
+
+// 构建 tls.Config
+func buildTLS(c *TLSConfig) (*tls.Config, error) {
+	conf := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify, // #nosec G402 由配置显式开启
+	}
+	if len(c.CAFile) > 0 {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("Redis CA 证书无效：" + c.CAFile)
+		}
+		conf.RootCAs = pool
+	}
+	if len(c.CertFile) > 0 || len(c.KeyFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return conf, nil
+}
+
+// 根据配置创建客户端
+// 单个 Addr 为 redis:// 或 rediss:// URL 时按 go-redis 的 URL 规则解析，
+// 查询参数含 master_name 时为哨兵，含 addr 时为集群，其余为单机，CacheConfig 中的 Password、DB 被忽略
+func newClient(cfg RedisConfig) (redis.UniversalClient, error) {
+	if len(cfg.Addr) == 1 && strings.Contains(cfg.Addr[0], "://") {
+		return newURLClient(cfg.Addr[0])
+	}
+	opts := &redis.UniversalOptions{
+		Addrs: cfg.Addr, DB: cfg.DB,
+		Username: cfg.Username, Password: cfg.Password,
+		MasterName:       cfg.MasterName,
+		SentinelUsername: cfg.SentinelUsername,
+		SentinelPassword: cfg.SentinelPassword,
+		MinIdleConns:     cfg.MinIdleConns,
+		PoolSize:         cfg.PoolSize,
+		DialTimeout:      cfg.DialTimeout,
+		ReadTimeout:      cfg.ReadTimeout,
+		WriteTimeout:     cfg.WriteTimeout,
+		PoolTimeout:      cfg.PoolTimeout,
+		MaxRetries:       cfg.MaxRetries,
+		MaxIdleConns:     cfg.MaxIdleConns,
+		MaxActiveConns:   cfg.MaxActiveConns,
+		ConnMaxIdleTime:  cfg.ConnMaxIdleTime,
+		ConnMaxLifetime:  cfg.ConnMaxLifetime,
+		ReadOnly:         cfg.ReadOnly,
+		RouteByLatency:   cfg.RouteByLatency,
+		RouteRandomly:    cfg.RouteRandomly,
+	}
+	if cfg.TLS != nil {
+		conf, err := buildTLS(cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		opts.TLSConfig = conf
+	}
+	// 哨兵模式下 UniversalClient 会把 ReadOnly 当作 ReplicaOnly，写命令也发往副本，
+	// 改用 FailoverClusterClient：只读命令发往副本，写命令发往主节点
+	if len(opts.MasterName) > 0 && (opts.ReadOnly || opts.RouteByLatency || opts.RouteRandomly) {
+		failover := opts.Failover()
+		failover.ReplicaOnly = true
+		return redis.NewFailoverClusterClient(failover), nil
+	}
+	return redis.NewUniversalClient(opts), nil
+}
+
+// 根据 URL 创建客户端
+func newURLClient(addr string) (redis.UniversalClient, error) {
+	u, perr := url.Parse(addr)
+	if perr != nil {
+		return nil, perr
+	}
+	query := u.Query()
+	switch {
+	case query.Has("master_name"):
+		opts, err := redis.ParseFailoverURL(addr)
+		if err != nil {
+			return nil, err
+		}
+		return redis.NewFailoverClient(opts), nil
+	case query.Has("addr"):
+		opts, err := redis.ParseClusterURL(addr)
+		if err != nil {
+			return nil, err
+		}
+		return redis.NewClusterClient(opts), nil
+	default:
+		opts, err := redis.ParseURL(addr)
+		if err != nil {
+			return nil, err
+		}
+		return redis.NewClient(opts), nil
+	}
+}
@@ -0,0 +1,37 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/livexy/plugin/cacher"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestNewClientSentinelReadOnly(t *testing.T) {
+	cfg := RedisConfig{CacheConfig: cacher.CacheConfig{Addr: []string{"127.0.0.1:26379"}}, MasterName: "mymaster"}
+	client, err := newClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := client.(*redis.Client); !ok {
+		t.Fatalf("client = %T", client)
+	}
+	_ = client.Close()
+
+	// 读写分离时写命令仍须发往主节点，不能使用只连副本的 FailoverClient
+	for _, c := range []RedisConfig{
+		{CacheConfig: cfg.CacheConfig, MasterName: "mymaster", ReadOnly: true},
+		{CacheConfig: cfg.CacheConfig, MasterName: "mymaster", RouteByLatency: true},
+		{CacheConfig: cfg.CacheConfig, MasterName: "mymaster", RouteRandomly: true},
+	} {
+		client, err = newClient(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := client.(*redis.ClusterClient); !ok {
+			t.Fatalf("client = %T", client)
+		}
+		_ = client.Close()
+	}
+}
@@ -0,0 +1,19 @@
+package plugin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/livexy/plugin/cacher"
+	"github.com/livexy/plugins/cachertest"
+)
+
+func TestConformance(t *testing.T) {
+	cachertest.Run(t, cachertest.Options{
+		Prefix: "test",
+		New: func(t *testing.T) (cacher.Cacher, func(time.Duration)) {
+			cache, mr := newTestCache(t)
+			return cache, mr.FastForward
+		},
+	})
+}
@@ -0,0 +1,545 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strconv"
+	"time"
+
+	"github.com/livexy/linq"
+	"github.com/livexy/plugins/rediser"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNotFound 键或字段不存在
+var ErrNotFound = rediser.ErrNotFound
+
+// CtxCacher 携带 context 并返回 error 的缓存接口
+type CtxCacher = rediser.CtxCacher
+
+var _ CtxCacher = (*redisCache)(nil)
+
+// 将 redis.Nil 转换为 ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, redis.Nil) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// 保存数据
+func (cache *redisCache) SetCtx(ctx context.Context, key string, value any, expiration time.Duration) error {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return err
+	}
+	err = cache.rdb.Set(ctx, ckey, value, expiration).Err()
+	if err == nil {
+		cache.invalidate(ctx, ckey)
+	}
+	return err
+}
+
+// 保存数据 键不存在时
+func (cache *redisCache) SetNXCtx(ctx context.Context, key string, value any, expiration time.Duration) (bool, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	ok, err := cache.rdb.SetNX(ctx, ckey, value, expiration).Result()
+	if ok {
+		cache.invalidate(ctx, ckey)
+	}
+	return ok, err
+}
+
+// 保存数据 键存在时
+func (cache *redisCache) SetXXCtx(ctx context.Context, key string, value any, expiration time.Duration) (bool, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	ok, err := cache.rdb.SetXX(ctx, ckey, value, expiration).Result()
+	if ok {
+		cache.invalidate(ctx, ckey)
+	}
+	return ok, err
+}
+
+// 累加
+func (cache *redisCache) IncrCtx(ctx context.Context, key string) (int64, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	val, err := cache.rdb.Incr(ctx, ckey).Result()
+	if err == nil {
+		cache.invalidate(ctx, ckey)
+	}
+	return val, err
+}
+func (cache *redisCache) IncrByCtx(ctx context.Context, key string, val int64) (int64, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	result, err := cache.rdb.IncrBy(ctx, ckey, val).Result()
+	if err == nil {
+		cache.invalidate(ctx, ckey)
+	}
+	return result, err
+}
+
+// 累减
+func (cache *redisCache) DecrCtx(ctx context.Context, key string) (int64, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	val, err := cache.rdb.Decr(ctx, ckey).Result()
+	if err == nil {
+		cache.invalidate(ctx, ckey)
+	}
+	return val, err
+}
+func (cache *redisCache) DecrByCtx(ctx context.Context, key string, val int64) (int64, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	result, err := cache.rdb.DecrBy(ctx, ckey, val).Result()
+	if err == nil {
+		cache.invalidate(ctx, ckey)
+	}
+	return result, err
+}
+
+// KEY是否存在
+func (cache *redisCache) ExistsCtx(ctx context.Context, keys ...string) (int64, error) {
+	ckeys, err := cache.getKeys(ctx, keys)
+	if err != nil {
+		return 0, err
+	}
+	return cache.rdb.Exists(ctx, ckeys...).Result()
+}
+
+// 获取数据 string，键不存在时返回 false
+func (cache *redisCache) GetCtx(ctx context.Context, key string) (string, bool, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return "", false, err
+	}
+	v, ok, local, version := cache.localGet(ckey)
+	if ok {
+		if val, ok := v.(string); ok {
+			return val, true, nil
+		}
+	}
+	val, err := cache.rdb.Get(ctx, ckey).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	cache.localSet(local, version, ckey, val)
+	return val, true, nil
+}
+
+// 批量获取数据，不存在的键对应 nil
+func (cache *redisCache) MGetCtx(ctx context.Context, keys ...string) ([]any, error) {
+	ckeys, err := cache.getKeys(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	return cache.rdb.MGet(ctx, ckeys...).Result()
+}
+
+// 获取数据 bytes，键不存在时返回 false
+func (cache *redisCache) GetBytesCtx(ctx context.Context, key string) ([]byte, bool, error) {
+	val, ok, err := cache.GetCtx(ctx, key)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	return []byte(val), true, nil
+}
+
+// 获取INT数据，键不存在时返回 ErrNotFound
+func (cache *redisCache) GetIntCtx(ctx context.Context, key string) (int, error) {
+	val, ok, err := cache.GetCtx(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, ErrNotFound
+	}
+	return strconv.Atoi(val)
+}
+
+// 获取INT64数据，键不存在时返回 ErrNotFound
+func (cache *redisCache) GetInt64Ctx(ctx context.Context, key string) (int64, error) {
+	val, ok, err := cache.GetCtx(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, ErrNotFound
+	}
+	return strconv.ParseInt(val, 10, 64)
+}
+
+// 设置新值并返回旧值，旧值不存在时返回 ErrNotFound
+func (cache *redisCache) GetSetCtx(ctx context.Context, key string, value any) (string, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	val, err := cache.rdb.GetSet(ctx, ckey, value).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+	cache.invalidate(ctx, ckey)
+	if err != nil {
+		return "", ErrNotFound
+	}
+	return val, nil
+}
+
+// 批量获取KEY 与 GetPatternScanCtx 相同，使用 SCAN 代替 KEYS 避免阻塞
+func (cache *redisCache) GetPatternKeysCtx(ctx context.Context, prefix string) ([]string, error) {
+	return cache.GetPatternScanCtx(ctx, prefix)
+}
+
+// 批量获取KEY 集群模式下遍历全部主节点
+// prefix 作为 MATCH 模式的开头，可含 * ? [] 通配，按原样匹配前缀请使用 ScanPrefix
+func (cache *redisCache) GetPatternScanCtx(ctx context.Context, prefix string) ([]string, error) {
+	match, err := cache.getKey(ctx, prefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	list := []string{}
+	for key, serr := range cache.scan(ctx, match) {
+		if serr != nil {
+			return linq.Uniq(list), serr
+		}
+		list = append(list, key)
+	}
+	return linq.Uniq(list), nil
+}
+
+// 自动加前缀 批量删除KEY
+func (cache *redisCache) DeleteCtx(ctx context.Context, keys ...string) error {
+	ckeys, err := cache.getKeys(ctx, keys)
+	if err != nil {
+		return err
+	}
+	return cache.DeleteKeysCtx(ctx, ckeys...)
+}
+func (cache *redisCache) UnlinkCtx(ctx context.Context, keys ...string) error {
+	ckeys, err := cache.getKeys(ctx, keys)
+	if err != nil {
+		return err
+	}
+	return cache.UnlinkKeysCtx(ctx, ckeys...)
+}
+
+// 无前缀 批量删除KEY
+func (cache *redisCache) DeleteKeysCtx(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	err := cache.rdb.Del(ctx, keys...).Err()
+	if err == nil {
+		cache.invalidate(ctx, keys...)
+	}
+	return err
+}
+func (cache *redisCache) UnlinkKeysCtx(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	err := cache.rdb.Unlink(ctx, keys...).Err()
+	if err == nil {
+		cache.invalidate(ctx, keys...)
+	}
+	return err
+}
+
+// 加锁 返回 true 表示已被锁定（加锁失败）
+func (cache *redisCache) LockStartCtx(ctx context.Context, key string, args ...int) (bool, error) {
+	seconds := lockSeconds
+	if len(args) > 0 {
+		seconds = args[0]
+	}
+	ok, err := cache.rdb.SetNX(ctx, cache.stableKey("Lock:"+key), 1, time.Duration(seconds)*time.Second).Result()
+	if err != nil {
+		return true, err
+	}
+	return !ok, nil
+}
+
+// 解锁
+func (cache *redisCache) LockEndCtx(ctx context.Context, key string) error {
+	return cache.rdb.Del(ctx, cache.stableKey("Lock:"+key)).Err()
+}
+
+// 存在
+func (cache *redisCache) HExistsCtx(ctx context.Context, key, field string) (bool, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	return cache.rdb.HExists(ctx, ckey, field).Result()
+}
+
+// 获取数据 string，字段不存在时返回 false
+func (cache *redisCache) HGetCtx(ctx context.Context, key, field string) (string, bool, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return "", false, err
+	}
+	val, err := cache.rdb.HGet(ctx, ckey, field).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return val, true, nil
+}
+
+// 获取数据 bytes，字段不存在时返回 false
+func (cache *redisCache) HGetBytesCtx(ctx context.Context, key, field string) ([]byte, bool, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return nil, false, err
+	}
+	val, err := cache.rdb.HGet(ctx, ckey, field).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return val, true, nil
+}
+
+// 获取INT64数据，字段不存在时返回 ErrNotFound
+func (cache *redisCache) HGetInt64Ctx(ctx context.Context, key, field string) (int64, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	val, err := cache.rdb.HGet(ctx, ckey, field).Result()
+	if err != nil {
+		return 0, notFound(err)
+	}
+	return strconv.ParseInt(val, 10, 64)
+}
+func (cache *redisCache) HGetAllCtx(ctx context.Context, key string) (map[string]string, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	v, ok, local, version := cache.localGet(ckey)
+	if ok {
+		if val, ok := v.(map[string]string); ok {
+			return maps.Clone(val), nil
+		}
+	}
+	val, err := cache.rdb.HGetAll(ctx, ckey).Result()
+	if err != nil {
+		return val, err
+	}
+	cache.localSet(local, version, ckey, maps.Clone(val))
+	return val, nil
+}
+func (cache *redisCache) HKeysCtx(ctx context.Context, key string) ([]string, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return cache.rdb.HKeys(ctx, ckey).Result()
+}
+func (cache *redisCache) HLenCtx(ctx context.Context, key string) (int64, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return cache.rdb.HLen(ctx, ckey).Result()
+}
+
+// 保存
+func (cache *redisCache) HSetCtx(ctx context.Context, key string, values ...any) (int64, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	val, err := cache.rdb.HSet(ctx, ckey, values...).Result()
+	if err == nil {
+		cache.invalidate(ctx, ckey)
+	}
+	return val, err
+}
+func (cache *redisCache) HIncrByCtx(ctx context.Context, key, field string, incr int64) (int64, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	val, err := cache.rdb.HIncrBy(ctx, ckey, field, incr).Result()
+	if err == nil {
+		cache.invalidate(ctx, ckey)
+	}
+	return val, err
+}
+func (cache *redisCache) HDelCtx(ctx context.Context, key string, fields ...string) (int64, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	val, err := cache.rdb.HDel(ctx, ckey, fields...).Result()
+	if err == nil {
+		cache.invalidate(ctx, ckey)
+	}
+	return val, err
+}
+
+// 命名空间视图只失效本命名空间
+func (cache *redisCache) FlushDBCtx(ctx context.Context) error {
+	if cache.ns != nil {
+		return cache.InvalidateNamespace(ctx)
+	}
+	err := cache.rdb.FlushDB(ctx).Err()
+	if err == nil {
+		cache.invalidate(ctx)
+	}
+	return err
+}
+
+func (cache *redisCache) ExpireCtx(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	ok, err := cache.rdb.Expire(ctx, ckey, expiration).Result()
+	if ok {
+		cache.invalidate(ctx, ckey)
+	}
+	return ok, err
+}
+func (cache *redisCache) PExpireCtx(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	ok, err := cache.rdb.PExpire(ctx, ckey, expiration).Result()
+	if ok {
+		cache.invalidate(ctx, ckey)
+	}
+	return ok, err
+}
+func (cache *redisCache) ExpireAtCtx(ctx context.Context, key string, tm time.Time) (bool, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	ok, err := cache.rdb.ExpireAt(ctx, ckey, tm).Result()
+	if ok {
+		cache.invalidate(ctx, ckey)
+	}
+	return ok, err
+}
+func (cache *redisCache) PExpireAtCtx(ctx context.Context, key string, tm time.Time) (bool, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	ok, err := cache.rdb.PExpireAt(ctx, ckey, tm).Result()
+	if ok {
+		cache.invalidate(ctx, ckey)
+	}
+	return ok, err
+}
+
+func (cache *redisCache) GetBitCtx(ctx context.Context, key string, offset int64) (int64, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return cache.rdb.GetBit(ctx, ckey, offset).Result()
+}
+func (cache *redisCache) SetBitCtx(ctx context.Context, key string, offset int64, val int) (int64, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	result, err := cache.rdb.SetBit(ctx, ckey, offset, val).Result()
+	if err == nil {
+		cache.invalidate(ctx, ckey)
+	}
+	return result, err
+}
+func (cache *redisCache) BitCountCtx(ctx context.Context, key string, start, end int64) (int64, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return cache.rdb.BitCount(ctx, ckey, &redis.BitCount{Start: start, End: end}).Result()
+}
+
+func (cache *redisCache) LLenCtx(ctx context.Context, key string) (int64, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return cache.rdb.LLen(ctx, ckey).Result()
+}
+func (cache *redisCache) LPushCtx(ctx context.Context, key string, values ...any) (int64, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return cache.rdb.LPush(ctx, ckey, values...).Result()
+}
+
+// 列表为空时返回 ErrNotFound
+func (cache *redisCache) LPopCtx(ctx context.Context, key string) ([]byte, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	val, err := cache.rdb.LPop(ctx, ckey).Bytes()
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return val, nil
+}
+func (cache *redisCache) RPushCtx(ctx context.Context, key string, values ...any) (int64, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return cache.rdb.RPush(ctx, ckey, values...).Result()
+}
+
+// 列表为空时返回 ErrNotFound
+func (cache *redisCache) RPopCtx(ctx context.Context, key string) ([]byte, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	val, err := cache.rdb.RPop(ctx, ckey).Bytes()
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return val, nil
+}
+func (cache *redisCache) LRangeCtx(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return cache.rdb.LRange(ctx, ckey, start, stop).Result()
+}
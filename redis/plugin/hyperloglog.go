@@ -0,0 +1,61 @@
+package plugin
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// 集群模式下 PFCount 多个键与 PFMerge 要求所有键位于同一槽，请使用相同的 hash tag
+
+func (cache *redisCache) PFAddCtx(ctx context.Context, key string, els ...any) (int64, error) {
+	ckey, err := cache.getKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return cache.rdb.PFAdd(ctx, ckey, els...).Result()
+}
+func (cache *redisCache) PFCountCtx(ctx context.Context, keys ...string) (int64, error) {
+	ckeys, err := cache.getKeys(ctx, keys)
+	if err != nil {
+		return 0, err
+	}
+	return cache.rdb.PFCount(ctx, ckeys...).Result()
+}
+func (cache *redisCache) PFMergeCtx(ctx context.Context, dest string, keys ...string) error {
+	ckeys, err := cache.getKeys(ctx, append([]string{dest}, keys...))
+	if err != nil {
+		return err
+	}
+	return cache.rdb.PFMerge(ctx, ckeys[0], ckeys[1:]...).Err()
+}
+
+// 添加基数统计元素，返回 1 表示估算值发生变化
+func (cache *redisCache) PFAdd(key string, els ...any) int64 {
+	val, err := cache.PFAddCtx(ctx, key, els...)
+	if err != nil {
+		cache.logger.Error("Redis PFAdd：", zap.String("key", key), zap.Any("els", els), zap.Error(err))
+		return 0
+	}
+	return val
+}
+
+// 估算基数，多个键时为并集的基数
+func (cache *redisCache) PFCount(keys ...string) int64 {
+	val, err := cache.PFCountCtx(ctx, keys...)
+	if err != nil {
+		cache.logger.Error("Redis PFCount：", zap.Strings("keys", keys), zap.Error(err))
+		return 0
+	}
+	return val
+}
+
+// 合并到 dest
+func (cache *redisCache) PFMerge(dest string, keys ...string) bool {
+	err := cache.PFMergeCtx(ctx, dest, keys...)
+	if err != nil {
+		cache.logger.Error("Redis PFMerge：", zap.String("dest", dest), zap.Strings("keys", keys), zap.Error(err))
+		return false
+	}
+	return true
+}
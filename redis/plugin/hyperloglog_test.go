@@ -0,0 +1,35 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	cache, _ := newTestCache(t)
+	if cache.PFAdd("h1", "a", "b", "c") != 1 || cache.PFAdd("h1", "a") != 0 {
+		t.Fatal("PFAdd")
+	}
+	// miniredis 多键计数为各键之和，此处取不相交的集合
+	cache.PFAdd("h2", "d", "e")
+	if n := cache.PFCount("h1"); n != 3 {
+		t.Fatalf("count = %d", n)
+	}
+	if n := cache.PFCount("h1", "h2"); n != 5 {
+		t.Fatalf("union count = %d", n)
+	}
+	cache.PFAdd("h3", "a", "e")
+	if !cache.PFMerge("m", "h1", "h2", "h3") || cache.PFCount("m") != 5 {
+		t.Fatal("PFMerge")
+	}
+
+	// 命名空间下的键随代数失效
+	ns := cache.Namespace("ns", nil)
+	ns.PFAdd("h", "a")
+	if err := ns.InvalidateNamespace(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := ns.PFCount("h"); n != 0 {
+		t.Fatalf("count after invalidate = %d", n)
+	}
+}
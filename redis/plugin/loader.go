@@ -0,0 +1,155 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/livexy/plugins/rediser"
+
+	"go.uber.org/zap"
+)
+
+// 其他实例回源期间轮询缓存的间隔
+const loadPollInterval = 50 * time.Millisecond
+
+// LoadOptions 缓存回源选项
+type LoadOptions = rediser.LoadOptions
+
+// GetOrLoadCtx 读取缓存对象，未命中时调用 loader 回源并写入缓存
+// 进程内相同 key 的并发未命中只回源一次（singleflight），回源使用脱离取消的 ctx，
+// 首个调用方取消不影响其他等待者；每个调用方的 ctx 取消时立即返回 ctx.Err()，回源在后台继续
+// loader 返回 ErrNotFound 表示数据不存在，此时返回 ErrNotFound
+func (cache *redisCache) GetOrLoadCtx(ctx context.Context, key string, expiration time.Duration, v any, loader func(ctx context.Context) (any, error), opt *LoadOptions) error {
+	o := LoadOptions{}
+	if opt != nil {
+		o = *opt
+	}
+	data, ok, err := cache.GetBytesCtx(ctx, key)
+	if err != nil {
+		// Redis 异常时降级为直接回源
+		cache.logger.Error("Redis GetOrLoad：", zap.String("key", key), zap.Error(err))
+	} else if ok {
+		return cache.decodeObject(data, v)
+	}
+	gkey, err := cache.getKey(ctx, key)
+	if err != nil {
+		gkey = cache.stableKey(key)
+	}
+	ch := cache.group.DoChan(gkey, func() (val any, lerr error) {
+		// DoChan 会在新的 goroutine 中重新抛出 panic，这里转为错误返回给全部等待者
+		defer func() {
+			if r := recover(); r != nil {
+				lerr = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		lctx := context.WithoutCancel(ctx)
+		if o.Timeout > 0 {
+			var cancel context.CancelFunc
+			lctx, cancel = context.WithTimeout(lctx, o.Timeout)
+			defer cancel()
+		}
+		return cache.load(lctx, key, expiration, loader, o)
+	})
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return res.Err
+		}
+		return cache.decodeObject(res.Val.([]byte), v)
+	}
+}
+
+// 回源并写入缓存，返回编码后的数据
+func (cache *redisCache) load(ctx context.Context, key string, expiration time.Duration, loader func(ctx context.Context) (any, error), o LoadOptions) ([]byte, error) {
+	if o.LockTTL > 0 {
+		lock, err := cache.ObtainLock(ctx, "Load:"+key, &LockOptions{TTL: o.LockTTL})
+		switch {
+		case err == nil:
+			defer func() {
+				rerr := lock.Release(ctx)
+				if rerr != nil && !errors.Is(rerr, ErrLockNotHeld) {
+					cache.logger.Error("Redis GetOrLoad 释放锁：", zap.String("key", key), zap.Error(rerr))
+				}
+			}()
+			// 抢到锁后再检查一次，其他实例可能刚刚写入
+			data, ok, gerr := cache.GetBytesCtx(ctx, key)
+			if gerr == nil && ok {
+				return data, nil
+			}
+		case errors.Is(err, ErrLockNotObtained):
+			data, ok := cache.waitLoaded(ctx, key, o.LockWait)
+			if ok {
+				return data, nil
+			}
+		default:
+			cache.logger.Error("Redis GetOrLoad 加锁：", zap.String("key", key), zap.Error(err))
+		}
+	}
+
+	val, err := loader(ctx)
+	if errors.Is(err, ErrNotFound) {
+		if o.NegativeTTL > 0 {
+			serr := cache.SetCtx(ctx, key, []byte{objectEmpty}, o.NegativeTTL)
+			if serr != nil {
+				cache.logger.Error("Redis GetOrLoad 缓存空值：", zap.String("key", key), zap.Error(serr))
+			}
+		}
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := cache.encodeObject(val)
+	if err != nil {
+		return nil, err
+	}
+	err = cache.SetCtx(ctx, key, data, jitter(expiration, o.Jitter))
+	if err != nil {
+		cache.logger.Error("Redis GetOrLoad 写入缓存：", zap.String("key", key), zap.Error(err))
+	}
+	return data, nil
+}
+
+// 等待其他实例回源写入缓存
+func (cache *redisCache) waitLoaded(ctx context.Context, key string, wait time.Duration) ([]byte, bool) {
+	deadline := time.Now().Add(wait)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-time.After(min(loadPollInterval, time.Until(deadline))):
+		}
+		data, ok, err := cache.GetBytesCtx(ctx, key)
+		if err != nil {
+			return nil, false
+		}
+		if ok {
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+// TTL 随机抖动
+func jitter(expiration time.Duration, ratio float64) time.Duration {
+	if expiration <= 0 || ratio <= 0 {
+		return expiration
+	}
+	delta := time.Duration(float64(expiration) * ratio)
+	if delta <= 0 {
+		return expiration
+	}
+	return expiration - delta + rand.N(2*delta+1)
+}
+
+// 读取缓存对象，未命中时回源
+func (cache *redisCache) GetOrLoad(key string, expiration time.Duration, v any, loader func() (any, error), opt *LoadOptions) error {
+	return cache.GetOrLoadCtx(ctx, key, expiration, v, func(context.Context) (any, error) {
+		return loader()
+	}, opt)
+}
@@ -0,0 +1,183 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type loaded struct {
+	Name string
+}
+
+func TestGetOrLoad(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	loader := func(context.Context) (any, error) {
+		calls.Add(1)
+		return loaded{Name: "a"}, nil
+	}
+	for range 2 {
+		var v loaded
+		if err := cache.GetOrLoadCtx(ctx, "user:1", time.Minute, &v, loader, nil); err != nil {
+			t.Fatal(err)
+		}
+		if v.Name != "a" {
+			t.Fatalf("v = %+v", v)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("loader calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestGetOrLoadNegative(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	loader := func(context.Context) (any, error) {
+		calls.Add(1)
+		return nil, ErrNotFound
+	}
+	opt := &LoadOptions{NegativeTTL: time.Minute}
+	for range 2 {
+		var v loaded
+		if err := cache.GetOrLoadCtx(ctx, "user:2", time.Minute, &v, loader, opt); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("err = %v", err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("loader calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestGetOrLoadSingleflight(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := func(context.Context) (any, error) {
+		calls.Add(1)
+		<-release
+		return loaded{Name: "b"}, nil
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for range 8 {
+		wg.Go(func() {
+			var v loaded
+			errs <- cache.GetOrLoadCtx(ctx, "user:3", time.Minute, &v, loader, nil)
+		})
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("loader calls = %d, want 1", calls.Load())
+	}
+}
+
+// 首个调用方取消不影响其他等待者，取消的调用方立即返回
+func TestGetOrLoadCancel(t *testing.T) {
+	cache, _ := newTestCache(t)
+
+	release := make(chan struct{})
+	loader := func(ctx context.Context) (any, error) {
+		select {
+		case <-release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return loaded{Name: "c"}, nil
+	}
+	first, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		var v loaded
+		done <- cache.GetOrLoadCtx(first, "user:4", time.Minute, &v, loader, nil)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	second := make(chan error, 1)
+	var v loaded
+	go func() {
+		second <- cache.GetOrLoadCtx(context.Background(), "user:4", time.Minute, &v, loader, nil)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("cancelled caller: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancelled caller did not return")
+	}
+	close(release)
+	if err := <-second; err != nil || v.Name != "c" {
+		t.Fatalf("waiter: %+v, %v", v, err)
+	}
+}
+
+func TestGetOrLoadTimeout(t *testing.T) {
+	cache, _ := newTestCache(t)
+
+	loader := func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	var v loaded
+	err := cache.GetOrLoadCtx(context.Background(), "user:5", time.Minute, &v, loader, &LoadOptions{Timeout: 20 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestGetOrLoadPanic(t *testing.T) {
+	cache, _ := newTestCache(t)
+
+	var v loaded
+	err := cache.GetOrLoadCtx(context.Background(), "user:6", time.Minute, &v, func(context.Context) (any, error) {
+		panic("boom")
+	}, nil)
+	if err == nil {
+		t.Fatal("panic not converted to error")
+	}
+}
+
+// 其他实例持有回源锁时等待其写入缓存
+func TestGetOrLoadLockWait(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+
+	lock, err := cache.ObtainLock(ctx, "Load:user:7", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = lock.Release(ctx) }()
+	go func() {
+		time.Sleep(60 * time.Millisecond)
+		_ = cache.SetObjectCtx(ctx, "user:7", loaded{Name: "other"}, time.Minute)
+	}()
+	var v loaded
+	err = cache.GetOrLoadCtx(ctx, "user:7", time.Minute, &v, func(context.Context) (any, error) {
+		return loaded{Name: "self"}, nil
+	}, &LoadOptions{LockTTL: time.Second, LockWait: time.Second})
+	if err != nil || v.Name != "other" {
+		t.Fatalf("v = %+v, %v", v, err)
+	}
+}
@@ -0,0 +1,245 @@
+package plugin
+
+import (
+	"container/list"
+	"context"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/livexy/plugins/rediser"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// LocalOptions 进程内一级缓存选项
+type LocalOptions = rediser.LocalOptions
+
+// CacheStats 一级与二级缓存命中统计
+type CacheStats = rediser.CacheStats
+
+type localEntry struct {
+	key    string
+	value  any
+	expire time.Time
+}
+
+// 失效版本的分片数
+const localStripes = 256
+
+// 带过期时间的 LRU，键为带前缀的完整键
+type localCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	ll    *list.List
+	items map[string]*list.Element
+
+	// 失效版本，按键哈希分片，读取 Redis 前记录，写入时版本变化说明期间收到过失效，放弃写入
+	versions [localStripes]atomic.Uint64
+
+	channel string
+	pubsub  *redis.PubSub
+}
+
+func newLocalCache(size int, ttl time.Duration) *localCache {
+	return &localCache{size: size, ttl: ttl, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+func localStripe(key string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % localStripes)
+}
+
+// 当前失效版本，未命中时在读取 Redis 前记录，传给 set
+func (l *localCache) version(key string) uint64 {
+	return l.versions[localStripe(key)].Load()
+}
+
+func (l *localCache) get(key string) (any, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	el, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*localEntry)
+	if time.Now().After(entry.expire) {
+		l.ll.Remove(el)
+		delete(l.items, key)
+		return nil, false
+	}
+	l.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+func (l *localCache) set(key string, value any, version uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.versions[localStripe(key)].Load() != version {
+		return
+	}
+	expire := time.Now().Add(l.ttl)
+	if el, ok := l.items[key]; ok {
+		entry := el.Value.(*localEntry)
+		entry.value, entry.expire = value, expire
+		l.ll.MoveToFront(el)
+		return
+	}
+	l.items[key] = l.ll.PushFront(&localEntry{key: key, value: value, expire: expire})
+	for l.ll.Len() > l.size {
+		el := l.ll.Back()
+		l.ll.Remove(el)
+		delete(l.items, el.Value.(*localEntry).key)
+	}
+}
+
+func (l *localCache) remove(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		l.versions[localStripe(key)].Add(1)
+		if el, ok := l.items[key]; ok {
+			l.ll.Remove(el)
+			delete(l.items, key)
+		}
+	}
+}
+
+func (l *localCache) purge() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := range l.versions {
+		l.versions[i].Add(1)
+	}
+	l.ll.Init()
+	l.items = make(map[string]*list.Element)
+}
+
+// EnableLocal 启用进程内一级缓存
+// Get、GetBytes、HGetAll 优先读取一级缓存，写入与删除时通过 Redis 频道广播失效
+// 所有实例须使用相同的频道，一级缓存只保证最终一致
+// 默认频道为当前实例的前缀（命名空间视图含命名空间名，不含代数）加 :__local__
+// 重复调用时替换为新的一级缓存，并关闭本实例此前启用的订阅，未单独启用的命名空间视图随之使用新的一级缓存
+func (cache *redisCache) EnableLocal(opt LocalOptions) error {
+	if opt.Size <= 0 {
+		opt.Size = 10000
+	}
+	if opt.TTL <= 0 {
+		opt.TTL = time.Minute
+	}
+	if len(opt.Channel) == 0 {
+		opt.Channel = cache.stableKey("__local__")
+	}
+	local := newLocalCache(opt.Size, opt.TTL)
+	local.channel = opt.Channel
+	local.pubsub = cache.rdb.Subscribe(ctx, opt.Channel)
+	// 等待订阅确认，确保启用后不会漏掉失效消息
+	if _, err := local.pubsub.Receive(ctx); err != nil {
+		_ = local.pubsub.Close()
+		return err
+	}
+	go cache.listenLocal(local)
+	if old := cache.local.Swap(local); old != nil {
+		cache.closeLocal(old)
+	}
+	return nil
+}
+
+// 关闭一级缓存的失效订阅，监听协程随之退出
+func (cache *redisCache) closeLocal(local *localCache) {
+	if err := local.pubsub.Close(); err != nil {
+		cache.logger.Error("关闭本地缓存订阅失败：", zap.Error(err))
+	}
+}
+
+// 监听失效广播，重连后重新订阅时清空一级缓存，避免断线期间漏掉的消息
+func (cache *redisCache) listenLocal(local *localCache) {
+	for msg := range local.pubsub.ChannelWithSubscriptions() {
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			if m.Kind == "subscribe" {
+				local.purge()
+			}
+		case *redis.Message:
+			if len(m.Payload) == 0 {
+				local.purge()
+				continue
+			}
+			local.remove(strings.Split(m.Payload, "\n")...)
+		}
+	}
+}
+
+// 当前使用的一级缓存，命名空间视图未单独启用时使用上级的一级缓存
+func (cache *redisCache) localCache() *localCache {
+	if local := cache.local.Load(); local != nil {
+		return local
+	}
+	if cache.ns != nil {
+		return cache.ns.parent.localCache()
+	}
+	return nil
+}
+
+// 失效本地缓存并广播，参数为带前缀的完整键，为空表示全部失效
+func (cache *redisCache) invalidate(ctx context.Context, ckeys ...string) {
+	local := cache.localCache()
+	if local == nil {
+		return
+	}
+	if len(ckeys) == 0 {
+		local.purge()
+	} else {
+		local.remove(ckeys...)
+	}
+	err := cache.rdb.Publish(ctx, local.channel, strings.Join(ckeys, "\n")).Err()
+	if err != nil {
+		cache.logger.Error("Redis 广播本地缓存失效：", zap.String("key", strings.Join(ckeys, ";")), zap.Error(err))
+	}
+}
+
+// 读取一级缓存并计数，未命中时返回读取 Redis 前的一级缓存与失效版本，供 localSet 使用
+func (cache *redisCache) localGet(ckey string) (any, bool, *localCache, uint64) {
+	local := cache.localCache()
+	if local == nil {
+		return nil, false, nil, 0
+	}
+	val, ok := local.get(ckey)
+	if ok {
+		atomic.AddUint64(&cache.stats.LocalHits, 1)
+		return val, true, local, 0
+	}
+	atomic.AddUint64(&cache.stats.LocalMisses, 1)
+	return nil, false, local, local.version(ckey)
+}
+
+// 写入一级缓存，读取 Redis 期间键被失效或一级缓存被替换时放弃写入
+func (cache *redisCache) localSet(local *localCache, version uint64, ckey string, val any) {
+	if local != nil && local == cache.localCache() {
+		local.set(ckey, val, version)
+	}
+}
+
+// 记录 Redis 命中
+func (cache *redisCache) redisHit(hit bool) {
+	if hit {
+		atomic.AddUint64(&cache.stats.RedisHits, 1)
+	} else {
+		atomic.AddUint64(&cache.stats.RedisMisses, 1)
+	}
+}
+
+// Stats 缓存命中统计
+func (cache *redisCache) Stats() CacheStats {
+	return CacheStats{
+		LocalHits:   atomic.LoadUint64(&cache.stats.LocalHits),
+		LocalMisses: atomic.LoadUint64(&cache.stats.LocalMisses),
+		RedisHits:   atomic.LoadUint64(&cache.stats.RedisHits),
+		RedisMisses: atomic.LoadUint64(&cache.stats.RedisMisses),
+	}
+}
@@ -0,0 +1,119 @@
+package plugin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/livexy/plugin/cacher"
+
+	"go.uber.org/zap"
+)
+
+func TestLocalCache(t *testing.T) {
+	cache, mr := newTestCache(t)
+	if err := cache.EnableLocal(LocalOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mr.Set(cache.stableKey("a"), "1"); err != nil {
+		t.Fatal(err)
+	}
+	if v := cache.Get("a"); v != "1" {
+		t.Fatalf("a = %q", v)
+	}
+	// 绕过插件直接修改，一级缓存仍返回旧值
+	if err := mr.Set(cache.stableKey("a"), "2"); err != nil {
+		t.Fatal(err)
+	}
+	if v := cache.Get("a"); v != "1" {
+		t.Fatalf("a = %q", v)
+	}
+	if stats := cache.Stats(); stats.LocalHits != 1 || stats.RedisHits != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	cache.Set("a", "3", 0)
+	if v := cache.Get("a"); v != "3" {
+		t.Fatalf("a = %q", v)
+	}
+}
+
+// 其他实例写入后通过频道失效本实例的一级缓存
+func TestLocalCacheBroadcast(t *testing.T) {
+	cache, mr := newTestCache(t)
+	c, err := NewRedisCacheWithConfig(RedisConfig{CacheConfig: cacher.CacheConfig{Addr: []string{mr.Addr()}, Prefix: "test"}}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+	other := c.(*redisCache)
+	for _, v := range []*redisCache{cache, other} {
+		if err = v.EnableLocal(LocalOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache.Set("a", "1", 0)
+	if v := cache.Get("a"); v != "1" {
+		t.Fatalf("a = %q", v)
+	}
+	other.Set("a", "2", 0)
+	deadline := time.Now().Add(time.Second)
+	for cache.Get("a") != "2" {
+		if time.Now().After(deadline) {
+			t.Fatal("local cache not invalidated")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// 读取 Redis 期间收到失效时不写入一级缓存
+func TestLocalCacheVersion(t *testing.T) {
+	local := newLocalCache(10, time.Minute)
+	version := local.version("k")
+	local.remove("k")
+	local.set("k", "old", version)
+	if _, ok := local.get("k"); ok {
+		t.Fatal("stale value stored after remove")
+	}
+	version = local.version("k")
+	local.purge()
+	local.set("k", "old", version)
+	if _, ok := local.get("k"); ok {
+		t.Fatal("stale value stored after purge")
+	}
+	local.set("k", "new", local.version("k"))
+	if v, ok := local.get("k"); !ok || v != "new" {
+		t.Fatalf("k = %v, %v", v, ok)
+	}
+}
+
+// 命名空间视图使用上级当前的一级缓存，上级重新启用后随之切换
+func TestLocalCacheNamespace(t *testing.T) {
+	cache, _ := newTestCache(t)
+	view := cache.Namespace("v", nil).(*redisCache)
+	if view.localCache() != nil {
+		t.Fatal("view has local cache before EnableLocal")
+	}
+	if err := cache.EnableLocal(LocalOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	first := cache.local.Load()
+	if view.localCache() != first {
+		t.Fatal("view does not use parent local cache")
+	}
+	if err := cache.EnableLocal(LocalOptions{Size: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if view.localCache() == first || view.localCache() != cache.local.Load() {
+		t.Fatal("view kept the replaced local cache")
+	}
+
+	if err := view.EnableLocal(LocalOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if view.localCache() == cache.local.Load() {
+		t.Fatal("view shares parent local cache after its own EnableLocal")
+	}
+	view.Close()
+	if view.localCache() != cache.local.Load() {
+		t.Fatal("view did not fall back to parent local cache after Close")
+	}
+}
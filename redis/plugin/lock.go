@@ -0,0 +1,242 @@
+package plugin
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/livexy/plugins/rediser"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrLockNotObtained 等待超时仍未获得锁
+	ErrLockNotObtained = rediser.ErrLockNotObtained
+	// ErrLockNotHeld 锁已过期或被他人持有
+	ErrLockNotHeld = rediser.ErrLockNotHeld
+)
+
+// 加锁成功时返回递增的 fencing token，失败返回 0
+// KEYS[1] 锁 KEYS[2] fencing 计数器 ARGV[1] 持有者令牌 ARGV[2] 过期毫秒 ARGV[3] 计数器过期毫秒
+var lockObtainScript = redis.NewScript(`
+if redis.call('set', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	local fence = redis.call('incr', KEYS[2])
+	redis.call('pexpire', KEYS[2], ARGV[3])
+	return fence
+end
+return 0
+`)
+
+// fencing 计数器在最后一次加锁后保留的时长，超过该时长未使用的锁名计数从 1 重新开始
+const lockFenceTTL = 30 * 24 * time.Hour
+
+// 转为毫秒，不足 1 毫秒的部分向上取整，避免向 Redis 发送 PX 0
+func lockMillis(d time.Duration) int64 {
+	return max((d + time.Millisecond - 1).Milliseconds(), 1)
+}
+
+// 仅当令牌一致时删除
+var lockReleaseScript = redis.NewScript(`
+if redis.call('get', KEYS[1]) == ARGV[1] then
+	return redis.call('del', KEYS[1])
+end
+return 0
+`)
+
+// 仅当令牌一致时续期
+var lockRefreshScript = redis.NewScript(`
+if redis.call('get', KEYS[1]) == ARGV[1] then
+	return redis.call('pexpire', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// 令牌一致时返回剩余毫秒，否则返回 -3
+var lockTTLScript = redis.NewScript(`
+if redis.call('get', KEYS[1]) == ARGV[1] then
+	return redis.call('pttl', KEYS[1])
+end
+return -3
+`)
+
+// LockOptions 分布式锁选项
+type LockOptions = rediser.LockOptions
+
+// Lock 带持有者令牌的分布式锁
+// 与 LockStart/LockEnd 使用不同的键，二者互不感知
+type Lock = rediser.Lock
+
+var _ Lock = (*redisLock)(nil)
+
+type redisLock struct {
+	cache *redisCache
+	key   string
+	token string
+	fence int64
+	ttl   time.Duration
+
+	once sync.Once
+	stop chan struct{}
+	lost chan struct{}
+}
+
+// ObtainLock 获取分布式锁，TTL 不足 1 毫秒时按 1 毫秒计
+// 锁与 fencing 计数器使用同一 hash tag，保证集群下位于同一槽，计数器在 lockFenceTTL 内未使用时过期
+func (cache *redisCache) ObtainLock(ctx context.Context, key string, opt *LockOptions) (Lock, error) {
+	o := LockOptions{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.TTL <= 0 {
+		o.TTL = lockSeconds * time.Second
+	}
+	if o.RetryMin <= 0 {
+		o.RetryMin = 10 * time.Millisecond
+	}
+	if o.RetryMax < o.RetryMin {
+		o.RetryMax = max(500*time.Millisecond, o.RetryMin)
+	}
+	token, err := lockToken()
+	if err != nil {
+		return nil, err
+	}
+	lock := &redisLock{
+		cache: cache, token: token, ttl: o.TTL,
+		key:  cache.stableKey("Lock:{" + key + "}"),
+		stop: make(chan struct{}), lost: make(chan struct{}),
+	}
+	fencekey := lock.key + ":fence"
+
+	var deadline time.Time
+	if o.WaitTimeout > 0 {
+		deadline = time.Now().Add(o.WaitTimeout)
+	}
+	backoff := o.RetryMin
+	for {
+		fence, err := lockObtainScript.Run(ctx, cache.rdb, []string{lock.key, fencekey}, token, lockMillis(o.TTL), lockFenceTTL.Milliseconds()).Int64()
+		if err != nil {
+			return nil, err
+		}
+		if fence > 0 {
+			lock.fence = fence
+			break
+		}
+		if deadline.IsZero() || !time.Now().Before(deadline) {
+			return nil, ErrLockNotObtained
+		}
+		// 指数退避并加入抖动，避免多个等待者同时重试
+		wait := min(backoff/2+rand.N(backoff/2+1), time.Until(deadline))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, o.RetryMax)
+	}
+	if o.AutoRenew {
+		go lock.watchdog()
+	}
+	return lock, nil
+}
+
+// 生成随机持有者令牌
+func lockToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := crand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// 看门狗 定期续期，续期失败时关闭 lost 通知持有者
+func (lock *redisLock) watchdog() {
+	ticker := time.NewTicker(max(lock.ttl/3, time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-lock.stop:
+			return
+		case <-ticker.C:
+			rctx, cancel := context.WithTimeout(context.Background(), lock.ttl/3+time.Second)
+			err := lock.Refresh(rctx, lock.ttl)
+			cancel()
+			if errors.Is(err, ErrLockNotHeld) {
+				lock.cache.logger.Error("Redis Lock 续期失败，锁已丢失", zap.String("key", lock.key))
+				close(lock.lost)
+				return
+			}
+			if err != nil {
+				lock.cache.logger.Error("Redis Lock 续期", zap.String("key", lock.key), zap.Error(err))
+			}
+		}
+	}
+}
+
+// Key 锁的完整键名
+func (lock *redisLock) Key() string {
+	return lock.key
+}
+
+// Token 持有者令牌
+func (lock *redisLock) Token() string {
+	return lock.token
+}
+
+// Fence 单调递增的 fencing token，写入下游存储时用于拒绝过期持有者
+func (lock *redisLock) Fence() int64 {
+	return lock.fence
+}
+
+// Lost 看门狗发现锁丢失时关闭
+func (lock *redisLock) Lost() <-chan struct{} {
+	return lock.lost
+}
+
+// Refresh 续期，ttl 小于等于 0 时使用加锁时的 TTL，锁已不属于自己时返回 ErrLockNotHeld
+func (lock *redisLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = lock.ttl
+	}
+	ok, err := lockRefreshScript.Run(ctx, lock.cache.rdb, []string{lock.key}, lock.token, lockMillis(ttl)).Int64()
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// TTL 剩余时间，锁已不属于自己时返回 ErrLockNotHeld
+func (lock *redisLock) TTL(ctx context.Context) (time.Duration, error) {
+	ms, err := lockTTLScript.Run(ctx, lock.cache.rdb, []string{lock.key}, lock.token).Int64()
+	if err != nil {
+		return 0, err
+	}
+	if ms == -3 {
+		return 0, ErrLockNotHeld
+	}
+	if ms < 0 {
+		return 0, nil
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// Release 释放锁，仅删除自己持有的锁，已丢失时返回 ErrLockNotHeld
+func (lock *redisLock) Release(ctx context.Context) error {
+	lock.once.Do(func() { close(lock.stop) })
+	ok, err := lockReleaseScript.Run(ctx, lock.cache.rdb, []string{lock.key}, lock.token).Int64()
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
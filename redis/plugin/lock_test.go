@@ -0,0 +1,136 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestObtainLock(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+
+	lock, err := cache.ObtainLock(ctx, "order", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock.Fence() != 1 || len(lock.Token()) == 0 {
+		t.Fatalf("fence = %d, token = %q", lock.Fence(), lock.Token())
+	}
+	if _, err = cache.ObtainLock(ctx, "order", nil); !errors.Is(err, ErrLockNotObtained) {
+		t.Fatalf("second obtain: %v", err)
+	}
+	ttl, err := lock.TTL(ctx)
+	if err != nil || ttl <= 0 || ttl > lockSeconds*time.Second {
+		t.Fatalf("ttl = %s, %v", ttl, err)
+	}
+	if err = lock.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err = lock.Release(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("release twice: %v", err)
+	}
+
+	next, err := cache.ObtainLock(ctx, "order", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Fence() != 2 {
+		t.Fatalf("fence = %d, want 2", next.Fence())
+	}
+	if err = lock.Refresh(ctx, time.Second); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("refresh by old holder: %v", err)
+	}
+}
+
+func TestObtainLockExpired(t *testing.T) {
+	cache, mr := newTestCache(t)
+	ctx := context.Background()
+
+	lock, err := cache.ObtainLock(ctx, "job", &LockOptions{TTL: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr.FastForward(2 * time.Second)
+	if _, err = lock.TTL(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("ttl after expiry: %v", err)
+	}
+	if err = lock.Refresh(ctx, time.Second); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("refresh after expiry: %v", err)
+	}
+	if _, err = cache.ObtainLock(ctx, "job", nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestObtainLockWait(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+
+	lock, err := cache.ObtainLock(ctx, "wait", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = lock.Release(ctx)
+	}()
+	next, err := cache.ObtainLock(ctx, "wait", &LockOptions{WaitTimeout: 2 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = next.Release(ctx)
+
+	_, err = cache.ObtainLock(ctx, "wait", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cctx, cancel := context.WithTimeout(ctx, 30*time.Millisecond)
+	defer cancel()
+	if _, err = cache.ObtainLock(cctx, "wait", &LockOptions{WaitTimeout: time.Minute}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("obtain with cancelled ctx: %v", err)
+	}
+}
+
+func TestLockLost(t *testing.T) {
+	cache, mr := newTestCache(t)
+	ctx := context.Background()
+
+	lock, err := cache.ObtainLock(ctx, "renew", &LockOptions{TTL: 30 * time.Millisecond, AutoRenew: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = lock.Release(ctx) }()
+	mr.Del(lock.Key())
+	select {
+	case <-lock.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("lost not closed after the lock key was removed")
+	}
+}
+
+// fencing 计数器带过期时间，不足 1 毫秒的 TTL 向上取整
+func TestObtainLockFenceTTL(t *testing.T) {
+	cache, mr := newTestCache(t)
+	ctx := context.Background()
+
+	lock, err := cache.ObtainLock(ctx, "short", &LockOptions{TTL: 500 * time.Microsecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fence := lock.Key() + ":fence"
+	if ttl := mr.TTL(fence); ttl != lockFenceTTL {
+		t.Fatalf("fence ttl = %s, want %s", ttl, lockFenceTTL)
+	}
+	if ttl := mr.TTL(lock.Key()); ttl != time.Millisecond {
+		t.Fatalf("lock ttl = %s, want 1ms", ttl)
+	}
+	if err = lock.Refresh(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	mr.FastForward(lockFenceTTL)
+	if mr.Exists(fence) {
+		t.Fatal("fence key did not expire")
+	}
+}
@@ -0,0 +1,200 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/livexy/plugins/rediser"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// PoolStats 连接池统计（命中、未命中、超时、空闲与总连接数）
+type PoolStats = rediser.PoolStats
+
+// Metrics 指标接口，由调用方对接 Prometheus 等系统
+type Metrics = rediser.Metrics
+
+// Tracer 可选的命令级追踪
+type Tracer = rediser.Tracer
+
+type observer struct {
+	metrics Metrics
+	tracer  Tracer
+}
+
+// 指标状态，命名空间视图与根实例共享
+type monitor struct {
+	observer atomic.Pointer[observer] // 未设置时为 nil
+	mu       sync.Mutex
+	stop     chan struct{} // 停止连接池统计上报
+}
+
+// UseMetrics 设置指标与追踪，interval 大于 0 时按该间隔上报连接池统计
+// 可重复调用替换，metrics、tracer 均为 nil 时关闭
+func (cache *redisCache) UseMetrics(metrics Metrics, tracer Tracer, interval time.Duration) {
+	m := cache.monitor
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+	if metrics == nil && tracer == nil {
+		m.observer.Store(nil)
+		return
+	}
+	m.observer.Store(&observer{metrics: metrics, tracer: tracer})
+	if metrics != nil && interval > 0 {
+		m.stop = make(chan struct{})
+		go cache.reportPool(metrics, interval, m.stop)
+	}
+}
+
+// PoolStats 当前连接池统计
+func (cache *redisCache) PoolStats() PoolStats {
+	return *cache.rdb.PoolStats()
+}
+
+// 定期上报连接池统计
+func (cache *redisCache) reportPool(metrics Metrics, interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			metrics.ObservePool(cache.PoolStats())
+		}
+	}
+}
+
+// 停止上报
+func (cache *redisCache) stopMetrics() {
+	m := cache.monitor
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+}
+
+// go-redis 钩子 在 NewRedisCache 中注册，未设置指标时直接透传
+type metricsHook struct {
+	cache *redisCache
+}
+
+func (h metricsHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h metricsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		obs := h.cache.monitor.observer.Load()
+		if obs == nil {
+			err := next(ctx, cmd)
+			h.cache.observeHit(ctx, nil, cmd, err)
+			return err
+		}
+		name := cmd.Name()
+		var end func(error)
+		if obs.tracer != nil {
+			ctx, end = obs.tracer.Start(ctx, name)
+		}
+		start := time.Now()
+		err := next(ctx, cmd)
+		cerr := cmdErr(err)
+		if obs.metrics != nil {
+			obs.metrics.ObserveCommand(name, time.Since(start), cerr)
+		}
+		h.cache.observeHit(ctx, obs.metrics, cmd, err)
+		if end != nil {
+			end(cerr)
+		}
+		return err
+	}
+}
+
+func (h metricsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		obs := h.cache.monitor.observer.Load()
+		if obs == nil {
+			err := next(ctx, cmds)
+			for _, cmd := range cmds {
+				h.cache.observeHit(ctx, nil, cmd, cmd.Err())
+			}
+			return err
+		}
+		var end func(error)
+		if obs.tracer != nil {
+			ctx, end = obs.tracer.Start(ctx, "pipeline")
+		}
+		start := time.Now()
+		err := next(ctx, cmds)
+		cerr := cmdErr(err)
+		if obs.metrics != nil {
+			obs.metrics.ObserveCommand("pipeline", time.Since(start), cerr)
+		}
+		for _, cmd := range cmds {
+			h.cache.observeHit(ctx, obs.metrics, cmd, cmd.Err())
+		}
+		if end != nil {
+			end(cerr)
+		}
+		return err
+	}
+}
+
+// 键不存在不计为错误
+func cmdErr(err error) error {
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
+
+// 内部读取（如命名空间代数）的上下文标记，不计入命中统计
+type internalKey struct{}
+
+func internalCtx(ctx context.Context) context.Context {
+	return context.WithValue(ctx, internalKey{}, true)
+}
+
+// 统计读命令命中情况，CacheStats 的 Redis 命中与 Metrics.ObserveHit 均以此为准，metrics 为 nil 时只计入 CacheStats
+// 单条命令在钩子返回后才设置错误，err 为命令的执行结果
+func (cache *redisCache) observeHit(ctx context.Context, metrics Metrics, cmd redis.Cmder, err error) {
+	hit := func(name string, ok bool) {
+		if ctx.Value(internalKey{}) != nil {
+			return
+		}
+		cache.redisHit(ok)
+		if metrics != nil {
+			metrics.ObserveHit(name, ok)
+		}
+	}
+	name := strings.ToLower(cmd.Name())
+	switch name {
+	case "get", "hget", "getex", "getdel":
+		if err == nil || errors.Is(err, redis.Nil) {
+			hit(name, err == nil)
+		}
+	case "mget", "hmget":
+		if c, ok := cmd.(*redis.SliceCmd); ok && err == nil {
+			for _, v := range c.Val() {
+				hit(name, v != nil)
+			}
+		}
+	case "hgetall":
+		if c, ok := cmd.(*redis.MapStringStringCmd); ok && err == nil {
+			hit(name, len(c.Val()) > 0)
+		}
+	}
+}
+
+var _ redis.Hook = metricsHook{}
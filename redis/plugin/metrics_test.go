@@ -0,0 +1,107 @@
+package plugin
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 记录命令、命中与追踪
+type recordMetrics struct {
+	mu       sync.Mutex
+	commands []string
+	errs     []error
+	hits     []string
+	spans    []string
+}
+
+func (m *recordMetrics) ObserveCommand(name string, _ time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.commands = append(m.commands, name)
+	m.errs = append(m.errs, err)
+}
+
+func (m *recordMetrics) ObserveHit(name string, hit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if hit {
+		m.hits = append(m.hits, name+":hit")
+	} else {
+		m.hits = append(m.hits, name+":miss")
+	}
+}
+
+func (m *recordMetrics) ObservePool(PoolStats) {}
+
+func (m *recordMetrics) Start(ctx context.Context, name string) (context.Context, func(error)) {
+	return ctx, func(err error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.spans = append(m.spans, name)
+	}
+}
+
+func TestMetricsHook(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+	m := &recordMetrics{}
+	cache.UseMetrics(m, m, 0)
+
+	cache.Set("a", "1", 0)
+	cache.Get("a")
+	cache.Get("missing")
+	cache.MGet("a", "missing")
+	cache.HGetAll("missing")
+	if _, err := cache.Pipeline().Get("a").Get("missing").Exec(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cache.HSet("a", "f", "v")
+
+	wantCommands := []string{"set", "get", "get", "mget", "hgetall", "pipeline", "hset"}
+	if !slices.Equal(m.commands, wantCommands) || !slices.Equal(m.spans, wantCommands) {
+		t.Fatalf("commands = %v, spans = %v", m.commands, m.spans)
+	}
+	// 键不存在不计为错误
+	for i, err := range m.errs[:6] {
+		if err != nil {
+			t.Fatalf("%s err = %v", m.commands[i], err)
+		}
+	}
+	if m.errs[6] == nil {
+		t.Fatal("hset on string key should report error")
+	}
+	wantHits := []string{"get:hit", "get:miss", "mget:hit", "mget:miss", "hgetall:miss", "get:hit", "get:miss"}
+	if !slices.Equal(m.hits, wantHits) {
+		t.Fatalf("hits = %v", m.hits)
+	}
+	// CacheStats 与 ObserveHit 同源
+	if stats := cache.Stats(); stats.RedisHits != 3 || stats.RedisMisses != 4 {
+		t.Fatalf("stats = %+v", stats)
+	}
+
+	// 关闭指标后不再上报，命中仍计入 CacheStats
+	cache.UseMetrics(nil, nil, 0)
+	cache.Get("a")
+	if len(m.commands) != len(wantCommands) || len(m.hits) != len(wantHits) {
+		t.Fatalf("observed after disable: %v %v", m.commands, m.hits)
+	}
+	if stats := cache.Stats(); stats.RedisHits != 4 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+// 命名空间视图与根实例共享指标，读取代数不计入命中
+func TestMetricsNamespace(t *testing.T) {
+	cache, _ := newTestCache(t)
+	m := &recordMetrics{}
+	ns := cache.Namespace("ns", nil)
+	ns.UseMetrics(m, nil, 0)
+	cache.Get("a")
+	ns.Get("a")
+	if !slices.Equal(m.hits, []string{"get:miss", "get:miss"}) || cache.Stats() != ns.Stats() {
+		t.Fatalf("hits = %v, stats = %+v %+v", m.hits, cache.Stats(), ns.Stats())
+	}
+}
@@ -0,0 +1,146 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/livexy/plugins/rediser"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// ErrNotNamespace 根实例不支持按命名空间失效
+var ErrNotNamespace = rediser.ErrNotNamespace
+
+// NamespaceOptions 命名空间选项
+type NamespaceOptions = rediser.NamespaceOptions
+
+// 命名空间视图
+// 键格式：上级前缀:名称:代数:键，代数递增后旧键不再可见，由过期时间自然清理
+type namespace struct {
+	parent  *redisCache
+	name    string // 已含 hash tag
+	refresh time.Duration
+	state   atomic.Pointer[generation]
+}
+
+// 本地缓存的代数
+type generation struct {
+	key     string // 代数键，上级代数变化时随之变化
+	val     int64
+	expires int64 // UnixNano
+}
+
+// Namespace 创建命名空间视图，可多级嵌套，与当前实例共享连接
+// 视图复制当前的序列化设置，UseStreamer 须在创建视图前调用
+// 视图默认使用上级当前的一级缓存，也可单独 EnableLocal，此时使用本视图的一级缓存与失效频道，不影响上级
+// 视图的 FlushDB 只失效本命名空间，Close 只关闭本视图启用的一级缓存订阅，不关闭共享连接
+// 代数只作用于缓存数据，锁、任务队列、限流、集合、有序集合、流与发布订阅频道不随命名空间失效
+func (cache *redisCache) Namespace(name string, opt *NamespaceOptions) RedisCacher {
+	o := NamespaceOptions{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.Refresh <= 0 {
+		o.Refresh = time.Second
+	}
+	if o.HashTag {
+		name = "{" + name + "}"
+	}
+	view := &redisCache{
+		rdb: cache.rdb, logger: cache.logger, prefix: cache.prefix,
+		ns:       &namespace{parent: cache, name: name, refresh: o.Refresh},
+		streamer: cache.streamer, compress: cache.compress,
+		group: cache.group, stats: cache.stats, monitor: cache.monitor,
+	}
+	return view
+}
+
+// 不带代数的键，锁、队列、限流、集合、有序集合、流与发布订阅频道不随命名空间失效
+func (cache *redisCache) stableKey(key string) string {
+	return cache.stablePrefix() + ":" + key
+}
+func (cache *redisCache) stableKeys(keys []string) []string {
+	ckeys := make([]string, 0, len(keys))
+	for _, v := range keys {
+		ckeys = append(ckeys, cache.stableKey(v))
+	}
+	return ckeys
+}
+func (cache *redisCache) stablePrefix() string {
+	if cache.ns == nil {
+		return cache.prefix
+	}
+	return cache.ns.parent.stablePrefix() + ":" + cache.ns.name
+}
+
+// 键前缀，命名空间视图含当前代数
+func (cache *redisCache) keyPrefix(ctx context.Context) (string, error) {
+	ns := cache.ns
+	if ns == nil {
+		return cache.prefix, nil
+	}
+	base, err := ns.base(ctx)
+	if err != nil {
+		return "", err
+	}
+	gen, err := ns.generation(ctx, base)
+	if err != nil {
+		return "", err
+	}
+	return base + ":" + strconv.FormatInt(gen, 10), nil
+}
+
+func (ns *namespace) base(ctx context.Context) (string, error) {
+	prefix, err := ns.parent.keyPrefix(ctx)
+	if err != nil {
+		return "", err
+	}
+	return prefix + ":" + ns.name, nil
+}
+
+// 当前代数，本地缓存过期或上级代数变化时重新读取，读取失败时沿用旧值
+// 没有旧值时返回错误，调用方不读写任何键，避免读到失效前的旧数据或写入无人读取的键
+func (ns *namespace) generation(ctx context.Context, base string) (int64, error) {
+	key := base + ":__gen__"
+	now := time.Now().UnixNano()
+	old := ns.state.Load()
+	if old != nil && old.key == key && old.expires > now {
+		return old.val, nil
+	}
+	val, err := ns.parent.rdb.Get(internalCtx(ctx), key).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		ns.parent.logger.Error("Redis 命名空间代数：", zap.String("key", key), zap.Error(err))
+		if old == nil || old.key != key {
+			return 0, err
+		}
+		val = old.val
+	}
+	ns.state.Store(&generation{key: key, val: val, expires: now + int64(ns.refresh)})
+	return val, nil
+}
+
+// InvalidateNamespace 递增代数，使命名空间（含下级命名空间）内的键全部失效
+// 本实例立即生效，其他实例在 Refresh 时间内生效
+func (cache *redisCache) InvalidateNamespace(ctx context.Context) error {
+	ns := cache.ns
+	if ns == nil {
+		return ErrNotNamespace
+	}
+	base, err := ns.base(ctx)
+	if err != nil {
+		return err
+	}
+	key := base + ":__gen__"
+	val, err := cache.rdb.Incr(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	ns.state.Store(&generation{key: key, val: val, expires: time.Now().UnixNano() + int64(ns.refresh)})
+	cache.invalidate(ctx)
+	return nil
+}
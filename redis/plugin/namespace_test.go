@@ -0,0 +1,111 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNamespaceInvalidate(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+	ns := cache.Namespace("user", nil)
+
+	if err := ns.SetCtx(ctx, "a", "1", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ns.SAddCtx(ctx, "s", "x"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ns.XAddCtx(ctx, "x", 0, map[string]any{"k": "v"}); err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("a", "root", 0)
+
+	if err := ns.InvalidateNamespace(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := ns.GetCtx(ctx, "a"); err != nil || ok {
+		t.Fatalf("get = %v, %v", ok, err)
+	}
+	if v := cache.Get("a"); v != "root" {
+		t.Fatalf("root a = %q", v)
+	}
+	// 集合与流不随代数失效
+	if n, err := ns.SCardCtx(ctx, "s"); err != nil || n != 1 {
+		t.Fatalf("scard = %d, %v", n, err)
+	}
+	if n, err := ns.XLenCtx(ctx, "x"); err != nil || n != 1 {
+		t.Fatalf("xlen = %d, %v", n, err)
+	}
+
+	if err := ns.SetCtx(ctx, "a", "2", 0); err != nil {
+		t.Fatal(err)
+	}
+	if v := ns.Get("a"); v != "2" {
+		t.Fatalf("a = %q", v)
+	}
+	if err := cache.InvalidateNamespace(ctx); !errors.Is(err, ErrNotNamespace) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestNamespaceNested(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+	parent := cache.Namespace("a", nil)
+	child := parent.Namespace("b", nil)
+
+	parent.Set("k", "p", 0)
+	child.Set("k", "c", 0)
+	if err := child.InvalidateNamespace(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if v := child.Get("k"); v != "" {
+		t.Fatalf("child k = %q", v)
+	}
+	if v := parent.Get("k"); v != "p" {
+		t.Fatalf("parent k = %q", v)
+	}
+
+	// 上级失效时下级一并失效
+	child.Set("k", "c", 0)
+	if err := parent.InvalidateNamespace(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if v := parent.Get("k"); v != "" {
+		t.Fatalf("parent k = %q", v)
+	}
+	if v := child.Get("k"); v != "" {
+		t.Fatalf("child k = %q", v)
+	}
+}
+
+// 读取代数失败且没有旧值时不读写任何键
+func TestNamespaceGenerationError(t *testing.T) {
+	cache, mr := newTestCache(t)
+	ctx := context.Background()
+	genKey := cache.stableKey("e:__gen__")
+	if err := mr.Set(genKey, "abc"); err != nil {
+		t.Fatal(err)
+	}
+	ns := cache.Namespace("e", nil)
+
+	if err := ns.SetCtx(ctx, "k", "1", 0); err == nil {
+		t.Fatal("set succeeded")
+	}
+	if _, _, err := ns.GetCtx(ctx, "k"); err == nil {
+		t.Fatal("get succeeded")
+	}
+	if _, err := ns.Pipeline().Set("k", "1", 0).Incr("n").Exec(ctx); err == nil {
+		t.Fatal("batch succeeded")
+	}
+	if keys := mr.Keys(); len(keys) != 1 || keys[0] != genKey {
+		t.Fatalf("keys = %v", keys)
+	}
+
+	mr.Del(genKey)
+	if err := ns.SetCtx(ctx, "k", "1", 0); err != nil {
+		t.Fatal(err)
+	}
+}
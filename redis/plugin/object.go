@@ -0,0 +1,128 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/livexy/plugin/streamer"
+	"github.com/livexy/plugins/rediser"
+
+	"github.com/golang/snappy"
+	"go.uber.org/zap"
+)
+
+// 对象缓存数据头，标记负载是否经过 snappy 压缩
+const (
+	objectRaw    byte = 0
+	objectSnappy byte = 1
+	objectEmpty  byte = 2 // 空值标记，数据不存在
+)
+
+// ErrObjectFormat 缓存内容不是 SetObject 写入的格式
+var ErrObjectFormat = rediser.ErrObjectFormat
+
+// 默认序列化 未配置 streamer/jsoner 插件时使用
+type stdJson struct{}
+
+func (s stdJson) Marshal(val any) ([]byte, error) {
+	return json.Marshal(val)
+}
+func (s stdJson) Unmarshal(buf []byte, val any) error {
+	return json.Unmarshal(buf, val)
+}
+
+// UseStreamer 设置对象缓存的序列化插件（gob-stream、sonic-json、go-json 等）
+// threshold 大于 0 时，序列化结果超过该字节数将使用 snappy 压缩
+// 需在初始化阶段调用，运行中切换会导致已缓存对象无法解析
+func (cache *redisCache) UseStreamer(s streamer.Streamer, threshold int) {
+	if s == nil {
+		s = stdJson{}
+	}
+	cache.streamer = s
+	cache.compress = threshold
+}
+
+// 序列化对象 首字节为格式标记
+func (cache *redisCache) encodeObject(v any) ([]byte, error) {
+	buf, err := cache.streamer.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if cache.compress > 0 && len(buf) > cache.compress {
+		data := make([]byte, 1, 1+snappy.MaxEncodedLen(len(buf)))
+		data[0] = objectSnappy
+		return append(data, snappy.Encode(nil, buf)...), nil
+	}
+	data := make([]byte, 1, 1+len(buf))
+	data[0] = objectRaw
+	return append(data, buf...), nil
+}
+
+// 反序列化对象
+func (cache *redisCache) decodeObject(data []byte, v any) error {
+	if len(data) == 0 {
+		return ErrObjectFormat
+	}
+	buf := data[1:]
+	switch data[0] {
+	case objectRaw:
+	case objectSnappy:
+		var err error
+		buf, err = snappy.Decode(nil, buf)
+		if err != nil {
+			return err
+		}
+	case objectEmpty:
+		return ErrNotFound
+	default:
+		return ErrObjectFormat
+	}
+	return cache.streamer.Unmarshal(buf, v)
+}
+
+// 序列化并保存对象
+func (cache *redisCache) SetObjectCtx(ctx context.Context, key string, v any, expiration time.Duration) error {
+	data, err := cache.encodeObject(v)
+	if err != nil {
+		return err
+	}
+	return cache.SetCtx(ctx, key, data, expiration)
+}
+
+// 获取并反序列化对象，键不存在或为空值标记时返回 false
+func (cache *redisCache) GetObjectCtx(ctx context.Context, key string, v any) (bool, error) {
+	data, ok, err := cache.GetBytesCtx(ctx, key)
+	if err != nil || !ok {
+		return false, err
+	}
+	err = cache.decodeObject(data, v)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// 保存对象
+func (cache *redisCache) SetObject(key string, v any, expiration time.Duration) bool {
+	err := cache.SetObjectCtx(ctx, key, v, expiration)
+	if err != nil {
+		cache.logger.Error("Redis SetObject：", zap.String("key", key), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// 获取对象
+func (cache *redisCache) GetObject(key string, v any) bool {
+	ok, err := cache.GetObjectCtx(ctx, key, v)
+	if err != nil {
+		cache.logger.Error("Redis GetObject：", zap.String("key", key), zap.Error(err))
+		return false
+	}
+	return ok
+}
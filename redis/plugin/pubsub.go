@@ -0,0 +1,173 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/livexy/plugins/rediser"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Message 订阅消息，频道与模式均已去掉缓存前缀
+type Message = rediser.Message
+
+// Subscription 订阅句柄
+// 连接断开后 go-redis 会自动重连并重新订阅，断线期间的消息不会补发
+type Subscription = rediser.Subscription
+
+var _ Subscription = (*redisSubscription)(nil)
+
+type redisSubscription struct {
+	pubsubs []*redis.PubSub
+	wg      sync.WaitGroup
+	once    sync.Once
+	err     error
+	done    chan struct{} // 全部监听协程退出后关闭
+}
+
+// Close 取消订阅后立即返回，不等待回调，可在回调中调用
+func (s *redisSubscription) Close() error {
+	s.once.Do(func() {
+		for _, ps := range s.pubsubs {
+			s.err = errors.Join(s.err, ps.Close())
+		}
+	})
+	return s.err
+}
+
+// Done 取消订阅且正在执行的回调全部结束后关闭
+func (s *redisSubscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// 发布消息，频道自动加前缀（命名空间视图不含代数），返回收到消息的订阅者数
+func (cache *redisCache) PublishCtx(ctx context.Context, channel string, msg any) (int64, error) {
+	return cache.rdb.Publish(ctx, cache.stableKey(channel), msg).Result()
+}
+
+// 发布消息
+func (cache *redisCache) Publish(channel string, msg any) int64 {
+	val, err := cache.PublishCtx(ctx, channel, msg)
+	if err != nil {
+		cache.logger.Error("Redis Publish：", zap.String("channel", channel), zap.Any("msg", msg), zap.Error(err))
+		return 0
+	}
+	return val
+}
+
+// Subscribe 订阅频道，频道自动加前缀，handler 在同一 goroutine 中依次调用
+func (cache *redisCache) Subscribe(ctx context.Context, handler func(Message), channels ...string) (Subscription, error) {
+	ps := cache.rdb.Subscribe(ctx, cache.stableKeys(channels)...)
+	return cache.listen(ctx, []*redis.PubSub{ps}, len(channels), func(m *redis.Message) {
+		handler(Message{Channel: cache.trimKey(m.Channel), Payload: m.Payload})
+	})
+}
+
+// PSubscribe 按模式订阅频道，模式自动加前缀
+func (cache *redisCache) PSubscribe(ctx context.Context, handler func(Message), patterns ...string) (Subscription, error) {
+	ps := cache.rdb.PSubscribe(ctx, cache.stableKeys(patterns)...)
+	return cache.listen(ctx, []*redis.PubSub{ps}, len(patterns), func(m *redis.Message) {
+		handler(Message{Channel: cache.trimKey(m.Channel), Pattern: cache.trimKey(m.Pattern), Payload: m.Payload})
+	})
+}
+
+// SubscribeExpired 订阅缓存前缀下键的过期事件，handler 收到去掉前缀的键名
+// 需要服务端开启 notify-keyspace-events（至少包含 Ex），集群模式下订阅全部主节点
+// 集群只订阅调用时的主节点，之后扩容或故障转移产生的新主节点不会自动订阅，拓扑变化后需 Close 并重新订阅
+func (cache *redisCache) SubscribeExpired(ctx context.Context, handler func(key string)) (Subscription, error) {
+	var pubsubs []*redis.PubSub
+	switch rdb := cache.rdb.(type) {
+	case *redis.ClusterClient:
+		var mu sync.Mutex
+		err := rdb.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+			ps := client.Subscribe(ctx, "__keyevent@0__:expired")
+			mu.Lock()
+			pubsubs = append(pubsubs, ps)
+			mu.Unlock()
+			return nil
+		})
+		if err != nil {
+			for _, ps := range pubsubs {
+				_ = ps.Close()
+			}
+			return nil, err
+		}
+	case *redis.Client:
+		pubsubs = append(pubsubs, rdb.Subscribe(ctx, "__keyevent@"+strconv.Itoa(rdb.Options().DB)+"__:expired"))
+	default:
+		pubsubs = append(pubsubs, cache.rdb.Subscribe(ctx, "__keyevent@0__:expired"))
+	}
+	lctx := context.WithoutCancel(ctx)
+	return cache.listen(ctx, pubsubs, 1, func(m *redis.Message) {
+		// 命名空间视图的前缀随代数变化，每次重新获取
+		prefix, err := cache.getKey(lctx, "")
+		if err != nil {
+			cache.logger.Error("Redis SubscribeExpired：", zap.String("key", m.Payload), zap.Error(err))
+			return
+		}
+		if strings.HasPrefix(m.Payload, prefix) {
+			handler(m.Payload[len(prefix):])
+		}
+	})
+}
+
+// 去掉缓存前缀
+func (cache *redisCache) trimKey(ckey string) string {
+	return strings.TrimPrefix(ckey, cache.stableKey(""))
+}
+
+// 等待每个 PubSub 的 n 个频道全部确认后启动监听，确保返回后不会漏掉消息
+// 等待期间已确认的频道收到的消息暂存，监听开始后先处理
+func (cache *redisCache) listen(ctx context.Context, pubsubs []*redis.PubSub, n int, fn func(*redis.Message)) (Subscription, error) {
+	sub := &redisSubscription{pubsubs: pubsubs, done: make(chan struct{})}
+	pending := make([][]*redis.Message, len(pubsubs))
+	for i, ps := range pubsubs {
+		for confirmed := 0; confirmed < n; {
+			v, err := ps.Receive(ctx)
+			if err != nil {
+				_ = sub.Close()
+				close(sub.done)
+				return nil, err
+			}
+			switch v := v.(type) {
+			case *redis.Subscription:
+				confirmed++
+			case *redis.Message:
+				pending[i] = append(pending[i], v)
+			}
+		}
+	}
+	for i, ps := range pubsubs {
+		sub.wg.Add(1)
+		go func() {
+			defer sub.wg.Done()
+			for _, m := range pending[i] {
+				cache.dispatch(m, fn)
+			}
+			for m := range ps.Channel() {
+				cache.dispatch(m, fn)
+			}
+		}()
+	}
+	go func() {
+		sub.wg.Wait()
+		close(sub.done)
+	}()
+	return sub, nil
+}
+
+// 执行回调，panic 只记录日志，不中断监听
+func (cache *redisCache) dispatch(m *redis.Message, fn func(*redis.Message)) {
+	defer func() {
+		if r := recover(); r != nil {
+			cache.logger.Error("Redis 订阅回调：", zap.String("channel", m.Channel), zap.Error(fmt.Errorf("panic: %v", r)))
+		}
+	}()
+	fn(m)
+}
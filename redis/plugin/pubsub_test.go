@@ -0,0 +1,87 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// 等待一条消息
+func recv[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+	var zero T
+	return zero
+}
+
+// 返回时全部频道均已确认，发布到任一频道都能收到
+func TestSubscribe(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+	ch := make(chan Message, 10)
+	sub, err := cache.Subscribe(ctx, func(m Message) {
+		if m.Payload == "panic" {
+			panic("handler")
+		}
+		ch <- m
+	}, "a", "b", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, channel := range []string{"c", "b", "a"} {
+		if n := cache.Publish(channel, "hi"); n != 1 {
+			t.Fatalf("publish %s = %d", channel, n)
+		}
+		if m := recv(t, ch); m.Channel != channel || m.Payload != "hi" || m.Pattern != "" {
+			t.Fatalf("message = %+v", m)
+		}
+	}
+
+	// 回调 panic 不中断监听
+	cache.Publish("a", "panic")
+	cache.Publish("a", "next")
+	if m := recv(t, ch); m.Payload != "next" {
+		t.Fatalf("message = %+v", m)
+	}
+
+	if err = sub.Close(); err != nil {
+		t.Fatal(err)
+	}
+	recv(t, sub.Done())
+}
+
+func TestPSubscribe(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ch := make(chan Message, 10)
+	sub, err := cache.PSubscribe(context.Background(), func(m Message) { ch <- m }, "p:*", "q:*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = sub.Close() }()
+	cache.Publish("x", "skip")
+	cache.Publish("q:1", "hi")
+	if m := recv(t, ch); m.Channel != "q:1" || m.Pattern != "q:*" || m.Payload != "hi" {
+		t.Fatalf("message = %+v", m)
+	}
+}
+
+// 过期事件只回调缓存前缀下的键，键名去掉前缀
+func TestSubscribeExpired(t *testing.T) {
+	cache, mr := newTestCache(t)
+	ch := make(chan string, 10)
+	sub, err := cache.SubscribeExpired(context.Background(), func(key string) { ch <- key })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = sub.Close() }()
+	mr.Publish("__keyevent@0__:expired", "other:k")
+	mr.Publish("__keyevent@0__:expired", "test:k")
+	if key := recv(t, ch); key != "k" {
+		t.Fatalf("key = %q", key)
+	}
+}
@@ -0,0 +1,340 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/livexy/plugins/rediser"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// 将到期的延迟任务移入流
+// KEYS[1] 延迟有序集合 KEYS[2] 流 ARGV[1] 当前毫秒 ARGV[2] 单次最多移动数
+// 成员格式：32位令牌|已尝试次数|负载
+var queuePromoteScript = redis.NewScript(`
+local items = redis.call('zrangebyscore', KEYS[1], '-inf', ARGV[1], 'limit', 0, ARGV[2])
+for _, v in ipairs(items) do
+	local sep = string.find(v, '|', 34, true)
+	redis.call('xadd', KEYS[2], '*', 'attempts', string.sub(v, 34, sep - 1), 'payload', string.sub(v, sep + 1))
+	redis.call('zrem', KEYS[1], v)
+end
+return #items
+`)
+
+// QueueOptions 任务队列选项
+type QueueOptions = rediser.QueueOptions
+
+// Queue 基于 Stream 消费组的可靠任务队列
+// 流、延迟集合、死信流使用同一 hash tag，集群下位于同一槽
+type Queue = rediser.Queue
+
+var _ Queue = (*redisQueue)(nil)
+
+type redisQueue struct {
+	cache   *redisCache
+	name    string
+	stream  string
+	delayed string
+	dead    string
+	opt     QueueOptions
+}
+
+// Job 出队的任务
+type Job = rediser.Job
+
+// NewQueue 创建任务队列，消费组不存在时自动创建
+func (cache *redisCache) NewQueue(ctx context.Context, name string, opt *QueueOptions) (Queue, error) {
+	o := QueueOptions{}
+	if opt != nil {
+		o = *opt
+	}
+	if len(o.Group) == 0 {
+		o.Group = "workers"
+	}
+	if o.Visibility <= 0 {
+		o.Visibility = 30 * time.Second
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 5
+	}
+	if o.Block <= 0 {
+		o.Block = 5 * time.Second
+	}
+	if o.Promote <= 0 {
+		o.Promote = time.Second
+	}
+	base := "Queue:{" + name + "}"
+	q := &redisQueue{
+		cache: cache, name: name, opt: o,
+		stream:  cache.stableKey(base),
+		delayed: cache.stableKey(base + ":delayed"),
+		dead:    cache.stableKey(base + ":dead"),
+	}
+	err := cache.rdb.XGroupCreateMkStream(ctx, q.stream, o.Group, "0").Err()
+	if err != nil && !isBusyGroup(err) {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Enqueue 入队，delay 大于 0 时为延迟任务
+func (q *redisQueue) Enqueue(ctx context.Context, payload []byte, delay time.Duration) error {
+	_, err := q.cache.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		return q.enqueue(ctx, pipe, payload, 0, delay)
+	})
+	return err
+}
+
+// 入队命令写入 pipe，由调用方决定是否与其他命令组成事务
+func (q *redisQueue) enqueue(ctx context.Context, pipe redis.Pipeliner, payload []byte, attempts int, delay time.Duration) error {
+	if delay <= 0 {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: q.stream, Values: []any{"attempts", attempts, "payload", payload},
+		})
+		return nil
+	}
+	token, err := lockToken()
+	if err != nil {
+		return err
+	}
+	member := make([]byte, 0, len(token)+len(payload)+8)
+	member = append(member, token...)
+	member = append(member, '|')
+	member = strconv.AppendInt(member, int64(attempts), 10)
+	member = append(member, '|')
+	member = append(member, payload...)
+	score := float64(time.Now().Add(delay).UnixMilli())
+	pipe.ZAdd(ctx, q.delayed, redis.Z{Score: score, Member: member})
+	return nil
+}
+
+// 将到期的延迟任务移入流
+func (q *redisQueue) promote(ctx context.Context) error {
+	return queuePromoteScript.Run(ctx, q.cache.rdb, []string{q.delayed, q.stream}, time.Now().UnixMilli(), 100).Err()
+}
+
+// Dequeue 出队，优先取回可见性超时的任务，无任务时阻塞至 Block 后返回 ErrNotFound
+// 超过最大尝试次数的任务直接转入死信队列
+// 到期的延迟任务在出队前移入流，阻塞期间到期的任务由 Run 的定时移入唤醒，单独使用 Dequeue 时需等到下次调用
+func (q *redisQueue) Dequeue(ctx context.Context, consumer string) (*Job, error) {
+	if err := q.promote(ctx); err != nil {
+		return nil, err
+	}
+	for {
+		msgs, _, err := q.cache.rdb.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream: q.stream, Group: q.opt.Group, Consumer: consumer,
+			MinIdle: q.opt.Visibility, Start: "0-0", Count: 1,
+		}).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(msgs) == 0 {
+			break
+		}
+		job, err := q.reclaimed(ctx, msgs[0])
+		if err != nil {
+			return nil, err
+		}
+		if job.Attempts <= q.opt.MaxAttempts {
+			return job, nil
+		}
+		err = q.deadLetter(ctx, job)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	streams, err := q.cache.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group: q.opt.Group, Consumer: consumer,
+		Streams: []string{q.stream, ">"}, Count: 1, Block: q.opt.Block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(streams) == 0 || len(streams[0].Messages) == 0 {
+		return nil, ErrNotFound
+	}
+	return newJob(streams[0].Messages[0], 1), nil
+}
+
+// 重新投递的任务，尝试次数需加上投递次数
+func (q *redisQueue) reclaimed(ctx context.Context, msg redis.XMessage) (*Job, error) {
+	pending, err := q.cache.rdb.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: q.stream, Group: q.opt.Group, Start: msg.ID, End: msg.ID, Count: 1,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	delivered := int64(1)
+	if len(pending) > 0 {
+		delivered = pending[0].RetryCount
+	}
+	return newJob(msg, int(delivered)), nil
+}
+
+func newJob(msg redis.XMessage, delivered int) *Job {
+	job := &Job{ID: msg.ID}
+	if v, ok := msg.Values["payload"].(string); ok {
+		job.Payload = []byte(v)
+	}
+	if v, ok := msg.Values["attempts"].(string); ok {
+		job.Attempts, _ = strconv.Atoi(v)
+	}
+	job.Attempts += delivered
+	return job
+}
+
+// 确认并删除流消息
+func (q *redisQueue) remove(ctx context.Context, pipe redis.Pipeliner, id string) {
+	pipe.XAck(ctx, q.stream, q.opt.Group, id)
+	pipe.XDel(ctx, q.stream, id)
+}
+
+// 转入死信队列
+func (q *redisQueue) deadLetter(ctx context.Context, job *Job) error {
+	_, err := q.cache.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: q.dead, Values: []any{"id", job.ID, "attempts", job.Attempts, "payload", job.Payload},
+		})
+		q.remove(ctx, pipe, job.ID)
+		return nil
+	})
+	return err
+}
+
+// Ack 处理成功，确认并删除任务
+func (q *redisQueue) Ack(ctx context.Context, job *Job) error {
+	_, err := q.cache.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		q.remove(ctx, pipe, job.ID)
+		return nil
+	})
+	return err
+}
+
+// Nack 处理失败，delay 后重试，达到最大尝试次数时转入死信队列
+// 重新入队与确认在同一 MULTI/EXEC 中执行，不会重复或丢失任务
+func (q *redisQueue) Nack(ctx context.Context, job *Job, delay time.Duration) error {
+	if job.Attempts >= q.opt.MaxAttempts {
+		return q.deadLetter(ctx, job)
+	}
+	_, err := q.cache.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if eerr := q.enqueue(ctx, pipe, job.Payload, job.Attempts, delay); eerr != nil {
+			return eerr
+		}
+		q.remove(ctx, pipe, job.ID)
+		return nil
+	})
+	return err
+}
+
+// Extend 重置任务的可见性超时，长任务处理期间定期调用
+func (q *redisQueue) Extend(ctx context.Context, job *Job, consumer string) error {
+	return q.cache.rdb.XClaimJustID(ctx, &redis.XClaimArgs{
+		Stream: q.stream, Group: q.opt.Group, Consumer: consumer, Messages: []string{job.ID},
+	}).Err()
+}
+
+// Len 待处理任务数（不含延迟任务）
+func (q *redisQueue) Len(ctx context.Context) (int64, error) {
+	return q.cache.rdb.XLen(ctx, q.stream).Result()
+}
+
+// DeadLetters 读取死信任务
+func (q *redisQueue) DeadLetters(ctx context.Context, count int64) ([]*Job, error) {
+	msgs, err := q.cache.rdb.XRangeN(ctx, q.dead, "-", "+", count).Result()
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*Job, 0, len(msgs))
+	for _, msg := range msgs {
+		jobs = append(jobs, newJob(msg, 0))
+	}
+	return jobs, nil
+}
+
+// Run 启动 concurrency 个 worker 处理任务，ctx 取消后不再出队，等待处理中的任务完成后返回
+// handler 返回错误或 panic 时按 Nack 处理，重试间隔随尝试次数线性增长
+// 运行期间按 Promote 间隔将到期的延迟任务移入流，阻塞中的 worker 随即被唤醒
+func (q *redisQueue) Run(ctx context.Context, consumer string, concurrency int, handler func(ctx context.Context, job *Job) error) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.promoteLoop(ctx)
+	}()
+	for i := range max(concurrency, 1) {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			q.work(ctx, name, handler)
+		}(consumer + "-" + strconv.Itoa(i))
+	}
+	wg.Wait()
+}
+
+// 定时将到期的延迟任务移入流
+func (q *redisQueue) promoteLoop(ctx context.Context) {
+	ticker := time.NewTicker(q.opt.Promote)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := q.promote(ctx); err != nil && ctx.Err() == nil {
+				q.cache.logger.Error("Redis Queue 延迟任务：", zap.String("queue", q.name), zap.Error(err))
+			}
+		}
+	}
+}
+
+func (q *redisQueue) work(ctx context.Context, consumer string, handler func(ctx context.Context, job *Job) error) {
+	for ctx.Err() == nil {
+		job, err := q.Dequeue(ctx, consumer)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			q.cache.logger.Error("Redis Queue 出队：", zap.String("queue", q.name), zap.Error(err))
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		// 已出队的任务不受取消影响，处理完成后再退出
+		jctx := context.WithoutCancel(ctx)
+		err = q.handle(jctx, job, handler)
+		if err == nil {
+			err = q.Ack(jctx, job)
+		} else {
+			q.cache.logger.Error("Redis Queue 任务失败：", zap.String("queue", q.name), zap.String("id", job.ID), zap.Int("attempts", job.Attempts), zap.Error(err))
+			err = q.Nack(jctx, job, time.Duration(job.Attempts)*time.Second)
+		}
+		if err != nil {
+			q.cache.logger.Error("Redis Queue 确认：", zap.String("queue", q.name), zap.String("id", job.ID), zap.Error(err))
+		}
+	}
+}
+
+// 执行任务，panic 转为错误
+func (q *redisQueue) handle(ctx context.Context, job *Job, handler func(ctx context.Context, job *Job) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
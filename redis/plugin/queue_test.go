@@ -0,0 +1,156 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestQueue(t *testing.T, opt *QueueOptions) (*redisQueue, *redisCache) {
+	t.Helper()
+	cache, _ := newTestCache(t)
+	q, err := cache.NewQueue(context.Background(), "mail", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q.(*redisQueue), cache
+}
+
+func TestQueueAck(t *testing.T) {
+	q, _ := newTestQueue(t, &QueueOptions{Block: 10 * time.Millisecond})
+	ctx := context.Background()
+
+	if err := q.Enqueue(ctx, []byte("hello"), 0); err != nil {
+		t.Fatal(err)
+	}
+	job, err := q.Dequeue(ctx, "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(job.Payload) != "hello" || job.Attempts != 1 {
+		t.Fatalf("job = %+v", job)
+	}
+	if err = q.Ack(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := q.Len(ctx); n != 0 {
+		t.Fatalf("len = %d", n)
+	}
+	if _, err = q.Dequeue(ctx, "c1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("empty queue: %v", err)
+	}
+}
+
+// Nack 重新入队与确认在同一事务中，尝试次数累加，超过上限转入死信
+func TestQueueNack(t *testing.T) {
+	q, _ := newTestQueue(t, &QueueOptions{Block: 10 * time.Millisecond, MaxAttempts: 2})
+	ctx := context.Background()
+
+	if err := q.Enqueue(ctx, []byte("retry"), 0); err != nil {
+		t.Fatal(err)
+	}
+	job, err := q.Dequeue(ctx, "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = q.Nack(ctx, job, 0); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := q.Len(ctx); n != 1 {
+		t.Fatalf("len after nack = %d, want 1", n)
+	}
+	job, err = q.Dequeue(ctx, "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", job.Attempts)
+	}
+	if err = q.Nack(ctx, job, 0); err != nil {
+		t.Fatal(err)
+	}
+	dead, err := q.DeadLetters(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dead) != 1 || string(dead[0].Payload) != "retry" || dead[0].Attempts != 2 {
+		t.Fatalf("dead = %+v", dead)
+	}
+	if n, _ := q.Len(ctx); n != 0 {
+		t.Fatalf("len after dead letter = %d", n)
+	}
+}
+
+func TestQueueDelayed(t *testing.T) {
+	q, _ := newTestQueue(t, &QueueOptions{Block: 10 * time.Millisecond})
+	ctx := context.Background()
+
+	if err := q.Enqueue(ctx, []byte("later"), 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Dequeue(ctx, "c1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("delayed job visible early: %v", err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	job, err := q.Dequeue(ctx, "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(job.Payload) != "later" {
+		t.Fatalf("job = %+v", job)
+	}
+}
+
+// 可见性超时后任务被其他消费者取回
+func TestQueueVisibility(t *testing.T) {
+	q, _ := newTestQueue(t, &QueueOptions{Block: 10 * time.Millisecond, Visibility: 20 * time.Millisecond})
+	ctx := context.Background()
+
+	if err := q.Enqueue(ctx, []byte("slow"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Dequeue(ctx, "c1"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	job, err := q.Dequeue(ctx, "c2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(job.Payload) != "slow" || job.Attempts != 2 {
+		t.Fatalf("job = %+v", job)
+	}
+}
+
+// Run 定时移入到期的延迟任务，阻塞中的 worker 无需等到下次出队
+func TestQueueRun(t *testing.T) {
+	q, _ := newTestQueue(t, &QueueOptions{Block: time.Minute, Promote: 10 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if err := q.Enqueue(ctx, []byte("a"), 0); err != nil {
+		t.Fatal(err)
+	}
+	var handled atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		q.Run(ctx, "w", 1, func(_ context.Context, job *Job) error {
+			if handled.Add(1) == 1 {
+				return q.Enqueue(context.Background(), []byte("b"), 30*time.Millisecond)
+			}
+			cancel()
+			return nil
+		})
+	}()
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("delayed job was not promoted while the worker was blocked")
+	}
+	<-done
+	if handled.Load() != 2 {
+		t.Fatalf("handled = %d, want 2", handled.Load())
+	}
+}
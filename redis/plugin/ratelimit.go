@@ -0,0 +1,149 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/livexy/plugins/rediser"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 限流脚本均只访问一个键，集群下安全；时间取 Redis 服务器时间，避免多实例时钟偏差
+
+// 固定窗口 返回 {计数, 剩余毫秒}
+// KEYS[1] 计数器 ARGV[1] 窗口毫秒 ARGV[2] 本次消耗
+var fixedWindowScript = redis.NewScript(`
+local n = redis.call('incrby', KEYS[1], ARGV[2])
+local ttl = redis.call('pttl', KEYS[1])
+if ttl < 0 then
+	redis.call('pexpire', KEYS[1], ARGV[1])
+	ttl = tonumber(ARGV[1])
+end
+return {n, ttl}
+`)
+
+// 滑动日志窗口 返回 {是否允许, 剩余次数, 重试毫秒}
+// KEYS[1] 有序集合 ARGV[1] 窗口毫秒 ARGV[2] 上限 ARGV[3] 随机成员后缀
+var slidingWindowScript = redis.NewScript(`
+pcall(redis.replicate_commands)
+local t = redis.call('time')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+redis.call('zremrangebyscore', KEYS[1], '-inf', now - window)
+local count = redis.call('zcard', KEYS[1])
+if count < limit then
+	redis.call('zadd', KEYS[1], now, now .. '-' .. ARGV[3])
+	redis.call('pexpire', KEYS[1], window)
+	return {1, limit - count - 1, 0}
+end
+local oldest = redis.call('zrange', KEYS[1], 0, 0, 'withscores')
+return {0, 0, tonumber(oldest[2]) + window - now}
+`)
+
+// 令牌桶 GCRA 返回 {是否允许, 剩余令牌, 重试毫秒, 重置毫秒}
+// KEYS[1] 理论到达时间 ARGV[1] 桶容量 ARGV[2] 周期内令牌数 ARGV[3] 周期毫秒 ARGV[4] 本次消耗
+var tokenBucketScript = redis.NewScript(`
+pcall(redis.replicate_commands)
+local t = redis.call('time')
+local now = tonumber(t[1]) * 1000 + tonumber(t[2]) / 1000
+local burst = tonumber(ARGV[1])
+local emission = tonumber(ARGV[3]) / tonumber(ARGV[2])
+local cost = tonumber(ARGV[4])
+local tat = tonumber(redis.call('get', KEYS[1]) or now)
+tat = math.max(tat, now)
+local newtat = tat + emission * cost
+local diff = now - (newtat - emission * burst)
+if diff < 0 then
+	return {0, 0, math.ceil(-diff), math.ceil(tat - now)}
+end
+local reset = newtat - now
+if reset > 0 then
+	redis.call('set', KEYS[1], string.format('%.3f', newtat), 'px', math.ceil(reset))
+end
+return {1, math.floor(diff / emission), 0, math.ceil(reset)}
+`)
+
+// ErrRateArgs 限流参数无效
+var ErrRateArgs = rediser.ErrRateArgs
+
+// RateResult 限流结果
+type RateResult = rediser.RateResult
+
+// 校验限流参数，时长按毫秒计算，不足 1 毫秒视为无效
+func checkRate(name string, val int64, dur time.Duration) error {
+	if val <= 0 {
+		return fmt.Errorf("%w：%s 须大于 0", ErrRateArgs, name)
+	}
+	if dur.Milliseconds() <= 0 {
+		return fmt.Errorf("%w：时长须不小于 1 毫秒", ErrRateArgs)
+	}
+	return nil
+}
+
+// AllowFixedWindow 固定窗口限流，window 内最多消耗 limit，本次消耗 cost
+// 被拒绝的请求同样计数，持续超限的调用方需等待窗口结束
+// limit、cost 须大于 0，window 须不小于 1 毫秒，否则返回 ErrRateArgs
+func (cache *redisCache) AllowFixedWindow(ctx context.Context, key string, limit int64, window time.Duration, cost int64) (RateResult, error) {
+	if err := checkRate("limit", limit, window); err != nil {
+		return RateResult{}, err
+	}
+	if cost <= 0 {
+		return RateResult{}, fmt.Errorf("%w：cost 须大于 0", ErrRateArgs)
+	}
+	vals, err := fixedWindowScript.Run(ctx, cache.rdb, []string{cache.stableKey("Rate:" + key)}, window.Milliseconds(), cost).Int64Slice()
+	if err != nil {
+		return RateResult{}, err
+	}
+	count, ttl := vals[0], time.Duration(vals[1])*time.Millisecond
+	result := RateResult{Allowed: count <= limit, Remaining: max(limit-count, 0), ResetAfter: ttl}
+	if !result.Allowed {
+		result.RetryAfter = ttl
+	}
+	return result, nil
+}
+
+// AllowSlidingWindow 滑动日志窗口限流，任意 window 区间内最多 limit 次，被拒绝的请求不计数
+// limit 须大于 0，window 须不小于 1 毫秒，否则返回 ErrRateArgs
+func (cache *redisCache) AllowSlidingWindow(ctx context.Context, key string, limit int64, window time.Duration) (RateResult, error) {
+	if err := checkRate("limit", limit, window); err != nil {
+		return RateResult{}, err
+	}
+	token, err := lockToken()
+	if err != nil {
+		return RateResult{}, err
+	}
+	vals, err := slidingWindowScript.Run(ctx, cache.rdb, []string{cache.stableKey("Rate:" + key)}, window.Milliseconds(), limit, token[:8]).Int64Slice()
+	if err != nil {
+		return RateResult{}, err
+	}
+	result := RateResult{Allowed: vals[0] == 1, Remaining: vals[1], ResetAfter: window}
+	if !result.Allowed {
+		result.RetryAfter = time.Duration(vals[2]) * time.Millisecond
+	}
+	return result, nil
+}
+
+// AllowTokenBucket 令牌桶限流（GCRA），每 period 补充 rate 个令牌，桶容量 burst，本次消耗 cost 个
+// rate、burst、cost 须大于 0，period 须不小于 1 毫秒，否则返回 ErrRateArgs
+func (cache *redisCache) AllowTokenBucket(ctx context.Context, key string, rate int64, period time.Duration, burst, cost int64) (RateResult, error) {
+	if err := checkRate("rate", rate, period); err != nil {
+		return RateResult{}, err
+	}
+	if burst <= 0 || cost <= 0 {
+		return RateResult{}, fmt.Errorf("%w：burst、cost 须大于 0", ErrRateArgs)
+	}
+	vals, err := tokenBucketScript.Run(ctx, cache.rdb, []string{cache.stableKey("Rate:" + key)},
+		burst, rate, period.Milliseconds(), cost).Int64Slice()
+	if err != nil {
+		return RateResult{}, err
+	}
+	return RateResult{
+		Allowed:    vals[0] == 1,
+		Remaining:  vals[1],
+		RetryAfter: time.Duration(vals[2]) * time.Millisecond,
+		ResetAfter: time.Duration(vals[3]) * time.Millisecond,
+	}, nil
+}
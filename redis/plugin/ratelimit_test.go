@@ -0,0 +1,102 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAllowFixedWindow(t *testing.T) {
+	cache, mr := newTestCache(t)
+	ctx := context.Background()
+
+	for i := range 3 {
+		res, err := cache.AllowFixedWindow(ctx, "api", 3, time.Minute, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed || res.Remaining != int64(2-i) {
+			t.Fatalf("call %d: %+v", i, res)
+		}
+	}
+	res, err := cache.AllowFixedWindow(ctx, "api", 3, time.Minute, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed || res.RetryAfter <= 0 {
+		t.Fatalf("over limit: %+v", res)
+	}
+	mr.FastForward(time.Minute)
+	if res, err = cache.AllowFixedWindow(ctx, "api", 3, time.Minute, 2); err != nil || !res.Allowed || res.Remaining != 1 {
+		t.Fatalf("next window: %+v, %v", res, err)
+	}
+	if res, err = cache.AllowFixedWindow(ctx, "api", 3, time.Minute, 2); err != nil || res.Allowed {
+		t.Fatalf("cost over limit: %+v, %v", res, err)
+	}
+}
+
+func TestAllowSlidingWindow(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+
+	for i := range 2 {
+		res, err := cache.AllowSlidingWindow(ctx, "login", 2, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed || res.Remaining != int64(1-i) {
+			t.Fatalf("call %d: %+v", i, res)
+		}
+	}
+	res, err := cache.AllowSlidingWindow(ctx, "login", 2, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed || res.RetryAfter <= 0 || res.RetryAfter > time.Minute {
+		t.Fatalf("over limit: %+v", res)
+	}
+}
+
+func TestAllowTokenBucket(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+
+	res, err := cache.AllowTokenBucket(ctx, "sms", 1, time.Minute, 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("burst: %+v", res)
+	}
+	res, err = cache.AllowTokenBucket(ctx, "sms", 1, time.Minute, 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed || res.RetryAfter <= 0 || res.RetryAfter > time.Minute {
+		t.Fatalf("empty bucket: %+v", res)
+	}
+}
+
+func TestRateArgs(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+
+	calls := []func() error{
+		func() error { _, err := cache.AllowFixedWindow(ctx, "k", 0, time.Second, 1); return err },
+		func() error { _, err := cache.AllowFixedWindow(ctx, "k", 1, 0, 1); return err },
+		func() error { _, err := cache.AllowFixedWindow(ctx, "k", 1, time.Microsecond, 1); return err },
+		func() error { _, err := cache.AllowFixedWindow(ctx, "k", 1, time.Second, 0); return err },
+		func() error { _, err := cache.AllowSlidingWindow(ctx, "k", -1, time.Second); return err },
+		func() error { _, err := cache.AllowSlidingWindow(ctx, "k", 1, -time.Second); return err },
+		func() error { _, err := cache.AllowTokenBucket(ctx, "k", 0, time.Second, 1, 1); return err },
+		func() error { _, err := cache.AllowTokenBucket(ctx, "k", 1, 0, 1, 1); return err },
+		func() error { _, err := cache.AllowTokenBucket(ctx, "k", 1, time.Second, 0, 1); return err },
+		func() error { _, err := cache.AllowTokenBucket(ctx, "k", 1, time.Second, 1, 0); return err },
+	}
+	for i, call := range calls {
+		if err := call(); !errors.Is(err, ErrRateArgs) {
+			t.Errorf("case %d: %v", i, err)
+		}
+	}
+}
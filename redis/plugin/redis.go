@@ -3,70 +3,96 @@ package plugin
 import (
 	"context"
 	"errors"
-	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/livexy/plugin/cacher"
-
-	"github.com/livexy/linq"
+	"github.com/livexy/plugin/streamer"
+	"github.com/livexy/plugins/rediser"
 
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
 var ctx = context.Background()
 
 const lockSeconds = 10
 
+// RedisCacher Redis 缓存插件提供的完整能力，定义在 rediser 包
+// 插件入口仍返回 cacher.Cacher，需要扩展能力时断言为该接口
+type RedisCacher = rediser.RedisCacher
+
+var _ RedisCacher = (*redisCache)(nil)
+
 type redisCache struct {
 	rdb    redis.UniversalClient
 	logger *zap.Logger
 	prefix string
+	ns     *namespace // 命名空间视图，根实例为 nil
+
+	streamer streamer.Streamer // 对象序列化
+	compress int               // 超过该字节数时压缩，0 不压缩
+
+	group *singleflight.Group        // 合并进程内并发回源
+	local atomic.Pointer[localCache] // 本实例启用的一级缓存，未启用时为 nil
+	stats *CacheStats                // 命中统计
+
+	monitor *monitor // 指标与追踪
 }
 
 // NewRedisCache 创建一个新的 Redis 缓存实例
 // 支持单节点和集群模式，根据 AppConfig 中的配置自动切换
 func NewRedisCache(cfg cacher.CacheConfig, logger *zap.Logger) (cacher.Cacher, error) {
-	cache := &redisCache{logger: logger, prefix: cfg.Prefix}
+	return NewRedisCacheWithConfig(RedisConfig{CacheConfig: cfg}, logger)
+}
+
+// NewRedisCacheWithConfig 使用扩展配置创建 Redis 缓存实例
+// 支持单节点、集群、哨兵，以及 TLS、ACL 用户和连接池参数
+func NewRedisCacheWithConfig(cfg RedisConfig, logger *zap.Logger) (RedisCacher, error) {
+	cache := &redisCache{logger: logger, prefix: cfg.Prefix, streamer: stdJson{}, group: &singleflight.Group{}, stats: &CacheStats{}, monitor: &monitor{}}
 	if len(cfg.Addr) == 0 {
 		return nil, errors.New("请在config.yaml中配置cache缓存")
 	}
 
-	var rdb redis.UniversalClient
-	if len(cfg.Addr) == 1 {
-		redisOptions := &redis.Options{
-			Addr: cfg.Addr[0], DB: cfg.DB,
-			MinIdleConns: cfg.MinIdleConns,
-			PoolSize:     cfg.PoolSize,
-		}
-		if len(cfg.Password) > 0 {
-			redisOptions.Password = cfg.Password
-		}
-		rdb = redis.NewClient(redisOptions)
-	} else {
-		redisOptions := &redis.ClusterOptions{
-			Addrs: cfg.Addr, MinIdleConns: cfg.MinIdleConns,
-			PoolSize: cfg.PoolSize,
-		}
-		if len(cfg.Password) > 0 {
-			redisOptions.Password = cfg.Password
-		}
-		rdb = redis.NewClusterClient(redisOptions)
+	rdb, err := newClient(cfg)
+	if err != nil {
+		return nil, err
 	}
-
-	_, err := rdb.Ping(ctx).Result()
+	_, err = rdb.Ping(ctx).Result()
 	if err != nil {
+		_ = rdb.Close()
 		return nil, err
 	}
+	rdb.AddHook(metricsHook{cache: cache})
 	cache.rdb = rdb
 	return cache, nil
 }
 
+// 自动加前缀
+func (cache *redisCache) getKey(ctx context.Context, key string) (string, error) {
+	prefix, err := cache.keyPrefix(ctx)
+	if err != nil {
+		return "", err
+	}
+	return prefix + ":" + key, nil
+}
+func (cache *redisCache) getKeys(ctx context.Context, keys []string) ([]string, error) {
+	prefix, err := cache.keyPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ckeys := make([]string, 0, len(keys))
+	for _, v := range keys {
+		ckeys = append(ckeys, prefix+":"+v)
+	}
+	return ckeys, nil
+}
+
 // 保存数据
 func (cache *redisCache) Set(key string, value any, expiration time.Duration) bool {
-	ckey := cache.prefix + ":" + key
-	err := cache.rdb.Set(ctx, ckey, value, expiration).Err()
+	err := cache.SetCtx(ctx, key, value, expiration)
 	if err != nil {
 		cache.logger.Error("Redis Set：", zap.String("key", key), zap.Any("value", value), zap.Error(err))
 		return false
@@ -76,8 +102,7 @@ func (cache *redisCache) Set(key string, value any, expiration time.Duration) bo
 
 // 保存数据
 func (cache *redisCache) SetNX(key string, value any, expiration time.Duration) bool {
-	ckey := cache.prefix + ":" + key
-	result, err := cache.rdb.SetNX(ctx, ckey, value, expiration).Result()
+	result, err := cache.SetNXCtx(ctx, key, value, expiration)
 	if err != nil {
 		cache.logger.Error("Redis SetNX：", zap.String("key", key), zap.Any("value", value), zap.Error(err))
 		return false
@@ -85,8 +110,7 @@ func (cache *redisCache) SetNX(key string, value any, expiration time.Duration)
 	return result
 }
 func (cache *redisCache) SetXX(key string, value any, expiration time.Duration) bool {
-	ckey := cache.prefix + ":" + key
-	result, err := cache.rdb.SetXX(ctx, ckey, value, expiration).Result()
+	result, err := cache.SetXXCtx(ctx, key, value, expiration)
 	if err != nil {
 		cache.logger.Error("Redis SetXX：", zap.String("key", key), zap.Any("value", value), zap.Error(err))
 		return false
@@ -96,8 +120,7 @@ func (cache *redisCache) SetXX(key string, value any, expiration time.Duration)
 
 // 保存数据
 func (cache *redisCache) Incr(key string) int64 {
-	ckey := cache.prefix + ":" + key
-	result, err := cache.rdb.Incr(ctx, ckey).Result()
+	result, err := cache.IncrCtx(ctx, key)
 	if err != nil {
 		cache.logger.Error("Redis Incr：", zap.String("key", key), zap.Error(err))
 		return 0
@@ -105,8 +128,7 @@ func (cache *redisCache) Incr(key string) int64 {
 	return result
 }
 func (cache *redisCache) Decr(key string) int64 {
-	ckey := cache.prefix + ":" + key
-	result, err := cache.rdb.Decr(ctx, ckey).Result()
+	result, err := cache.DecrCtx(ctx, key)
 	if err != nil {
 		cache.logger.Error("Redis Decr：", zap.String("key", key), zap.Error(err))
 		return 0
@@ -116,8 +138,7 @@ func (cache *redisCache) Decr(key string) int64 {
 
 // 保存数据
 func (cache *redisCache) IncrBy(key string, val int64) int64 {
-	ckey := cache.prefix + ":" + key
-	result, err := cache.rdb.IncrBy(ctx, ckey, val).Result()
+	result, err := cache.IncrByCtx(ctx, key, val)
 	if err != nil {
 		cache.logger.Error("Redis IncrBy：", zap.String("key", key), zap.Int64("value", val), zap.Error(err))
 		return 0
@@ -125,8 +146,7 @@ func (cache *redisCache) IncrBy(key string, val int64) int64 {
 	return result
 }
 func (cache *redisCache) DecrBy(key string, val int64) int64 {
-	ckey := cache.prefix + ":" + key
-	result, err := cache.rdb.DecrBy(ctx, ckey, val).Result()
+	result, err := cache.DecrByCtx(ctx, key, val)
 	if err != nil {
 		cache.logger.Error("Redis DecrBy：", zap.String("key", key), zap.Int64("value", val), zap.Error(err))
 		return 0
@@ -136,10 +156,7 @@ func (cache *redisCache) DecrBy(key string, val int64) int64 {
 
 // KEY是否存在
 func (cache *redisCache) Exists(keys ...string) int64 {
-	for i := range keys {
-		keys[i] = cache.prefix + ":" + keys[i]
-	}
-	result, err := cache.rdb.Exists(ctx, keys...).Result()
+	result, err := cache.ExistsCtx(ctx, keys...)
 	if err != nil {
 		cache.logger.Error("Redis Exists：", zap.String("key", strings.Join(keys, ";")), zap.Error(err))
 		return 0
@@ -149,19 +166,11 @@ func (cache *redisCache) Exists(keys ...string) int64 {
 
 // 获取数据 string
 func (cache *redisCache) Get(key string) string {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.Get(ctx, ckey).Result()
-	if err != nil {
-		return ""
-	}
+	val, _, _ := cache.GetCtx(ctx, key)
 	return val
 }
-func (cache *redisCache) MGet(ks ...string) []any {
-	keys := make([]string, 0, len(ks))
-	for _, v := range ks {
-		keys = append(keys, cache.prefix+":"+v)
-	}
-	val, err := cache.rdb.MGet(ctx, keys...).Result()
+func (cache *redisCache) MGet(keys ...string) []any {
+	val, err := cache.MGetCtx(ctx, keys...)
 	if err != nil {
 		cache.logger.Error("Redis MGet：", zap.String("key", strings.Join(keys, ";")), zap.Error(err))
 		return nil
@@ -171,18 +180,13 @@ func (cache *redisCache) MGet(ks ...string) []any {
 
 // 获取数据 bytes
 func (cache *redisCache) GetBytes(key string) []byte {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.Get(ctx, ckey).Bytes()
-	if err != nil {
-		return nil
-	}
+	val, _, _ := cache.GetBytesCtx(ctx, key)
 	return val
 }
 
 // 获取INT数据
 func (cache *redisCache) GetInt(key string) int {
-	sval := cache.Get(key)
-	ival, err := strconv.Atoi(sval)
+	ival, err := cache.GetIntCtx(ctx, key)
 	if err != nil {
 		ival = 0
 	}
@@ -191,8 +195,7 @@ func (cache *redisCache) GetInt(key string) int {
 
 // 获取INT64数据
 func (cache *redisCache) GetInt64(key string) int64 {
-	sval := cache.Get(key)
-	ival, err := strconv.ParseInt(sval, 10, 64)
+	ival, err := cache.GetInt64Ctx(ctx, key)
 	if err != nil {
 		ival = 0
 	}
@@ -201,10 +204,11 @@ func (cache *redisCache) GetInt64(key string) int64 {
 
 // 持久化 不过期存储
 func (cache *redisCache) GetSet(key string, value any) string {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.GetSet(ctx, ckey, value).Result()
+	val, err := cache.GetSetCtx(ctx, key, value)
 	if err != nil {
-		cache.logger.Error("Redis GetSet：", zap.String("key", key), zap.Any("value", value), zap.Error(err))
+		if !errors.Is(err, ErrNotFound) {
+			cache.logger.Error("Redis GetSet：", zap.String("key", key), zap.Any("value", value), zap.Error(err))
+		}
 		return ""
 	}
 	return val
@@ -212,38 +216,22 @@ func (cache *redisCache) GetSet(key string, value any) string {
 
 // 批量获取KEY
 func (cache *redisCache) GetPatternKeys(prefix string) []string {
-	key := prefix + "*"
-	return cache.rdb.Keys(ctx, cache.prefix+":"+key).Val()
+	val, _ := cache.GetPatternKeysCtx(ctx, prefix)
+	return val
 }
 
 // 批量获取KEY
 func (cache *redisCache) GetPatternScan(prefix string) []string {
-	list := []string{}
-	key := prefix + "*"
-	var cursor uint64
-	for {
-		keys, cur, err := cache.rdb.Scan(ctx, cursor, cache.prefix+":"+key, 20).Result()
-		if err != nil {
-			cache.logger.Error("Redis GetPatternScan：", zap.String("prefix", prefix), zap.Error(err))
-			break
-		}
-		if len(keys) > 0 {
-			list = append(list, keys...)
-		}
-		cursor = cur
-		if cur == 0 {
-			break
-		}
+	list, err := cache.GetPatternScanCtx(ctx, prefix)
+	if err != nil {
+		cache.logger.Error("Redis GetPatternScan：", zap.String("prefix", prefix), zap.Error(err))
 	}
-	return linq.Uniq(list)
+	return list
 }
 
 // 自动加前缀 批量删除KEY
 func (cache *redisCache) Delete(keys ...string) bool {
-	for i, v := range keys {
-		keys[i] = cache.prefix + ":" + v
-	}
-	_, err := cache.rdb.Del(ctx, keys...).Result()
+	err := cache.DeleteCtx(ctx, keys...)
 	if err != nil {
 		cache.logger.Error("Redis Delete：", zap.String("key", strings.Join(keys, ";")), zap.Error(err))
 		return false
@@ -251,10 +239,7 @@ func (cache *redisCache) Delete(keys ...string) bool {
 	return true
 }
 func (cache *redisCache) Unlink(keys ...string) bool {
-	for i, v := range keys {
-		keys[i] = cache.prefix + ":" + v
-	}
-	_, err := cache.rdb.Unlink(ctx, keys...).Result()
+	err := cache.UnlinkCtx(ctx, keys...)
 	if err != nil {
 		cache.logger.Error("Redis Unlink：", zap.String("key", strings.Join(keys, ";")), zap.Error(err))
 		return false
@@ -264,10 +249,7 @@ func (cache *redisCache) Unlink(keys ...string) bool {
 
 // 无前缀 批量删除KEY
 func (cache *redisCache) DeleteKeys(keys ...string) bool {
-	if len(keys) == 0 {
-		return true
-	}
-	_, err := cache.rdb.Del(ctx, keys...).Result()
+	err := cache.DeleteKeysCtx(ctx, keys...)
 	if err != nil {
 		cache.logger.Error("Redis DeleteKeys：", zap.String("key", strings.Join(keys, ";")), zap.Error(err))
 		return false
@@ -275,10 +257,7 @@ func (cache *redisCache) DeleteKeys(keys ...string) bool {
 	return true
 }
 func (cache *redisCache) UnlinkKeys(keys ...string) bool {
-	if len(keys) == 0 {
-		return true
-	}
-	_, err := cache.rdb.Unlink(ctx, keys...).Result()
+	err := cache.UnlinkKeysCtx(ctx, keys...)
 	if err != nil {
 		cache.logger.Error("Redis UnlinkKeys：", zap.String("key", strings.Join(keys, ";")), zap.Error(err))
 		return false
@@ -288,22 +267,30 @@ func (cache *redisCache) UnlinkKeys(keys ...string) bool {
 
 // 加锁
 func (cache *redisCache) LockStart(key string, args ...int) bool {
-	seconds := lockSeconds
-	if len(args) > 0 {
-		seconds = args[0]
+	locked, err := cache.LockStartCtx(ctx, key, args...)
+	if err != nil {
+		cache.logger.Error("Redis LockStart：", zap.String("key", key), zap.Error(err))
 	}
-	key = "Lock:" + key
-	return !cache.SetNX(key, 1, time.Duration(seconds)*time.Second)
+	return locked
 }
 
 // 解锁
 func (cache *redisCache) LockEnd(key string) {
-	key = "Lock:" + key
-	cache.Delete(key)
+	err := cache.LockEndCtx(ctx, key)
+	if err != nil {
+		cache.logger.Error("Redis LockEnd：", zap.String("key", key), zap.Error(err))
+	}
 }
 
 // 关闭释放连接
 func (cache *redisCache) Close() {
+	if local := cache.local.Swap(nil); local != nil {
+		cache.closeLocal(local)
+	}
+	if cache.ns != nil {
+		return
+	}
+	cache.stopMetrics()
 	if cache.rdb != nil {
 		if err := cache.rdb.Close(); err != nil {
 			cache.logger.Error("关闭连接失败：", zap.Error(err))
@@ -313,8 +300,7 @@ func (cache *redisCache) Close() {
 
 // 存在
 func (cache *redisCache) HExists(key, field string) bool {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.HExists(ctx, ckey, field).Result()
+	val, err := cache.HExistsCtx(ctx, key, field)
 	if err != nil {
 		cache.logger.Error("Redis HExists：", zap.String("key", key), zap.String("field", field), zap.Error(err))
 		return false
@@ -324,59 +310,41 @@ func (cache *redisCache) HExists(key, field string) bool {
 
 // 获取数据 string
 func (cache *redisCache) HGet(key, field string) string {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.HGet(ctx, ckey, field).Result()
-	if err != nil {
-		//cache.logger.Error("Redis HGet：", zap.String("key", key), zap.String("field", field), zap.Error(err))
-		return ""
-	}
+	val, _, _ := cache.HGetCtx(ctx, key, field)
 	return val
 }
 
 // 获取数据 bytes
 func (cache *redisCache) HGetBytes(key, field string) []byte {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.HGet(ctx, ckey, field).Bytes()
-	if err != nil {
-		//cache.logger.Error("Redis HGetBytes：", zap.String("key", key), zap.String("field", field), zap.Error(err))
-		return nil
-	}
+	val, _, _ := cache.HGetBytesCtx(ctx, key, field)
 	return val
 }
 
 // 获取INT64数据
 func (cache *redisCache) HGetInt64(key, field string) int64 {
-	sval := cache.HGet(key, field)
-	ival, err := strconv.ParseInt(sval, 10, 64)
+	ival, err := cache.HGetInt64Ctx(ctx, key, field)
 	if err != nil {
 		ival = 0
 	}
 	return ival
 }
 func (cache *redisCache) HGetAll(key string) map[string]string {
-	all := make(map[string]string)
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.HGetAll(ctx, ckey).Result()
+	val, err := cache.HGetAllCtx(ctx, key)
 	if err != nil {
-		return all
+		return make(map[string]string)
 	}
-	all = val
-	return all
+	return val
 }
 func (cache *redisCache) HKeys(key string) []string {
-	var all []string
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.HKeys(ctx, ckey).Result()
+	val, err := cache.HKeysCtx(ctx, key)
 	if err != nil {
 		cache.logger.Error("Redis HKeys：", zap.String("key", key), zap.Error(err))
-		return all
+		return nil
 	}
-	all = val
-	return all
+	return val
 }
 func (cache *redisCache) HLen(key string) int64 {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.HLen(ctx, ckey).Result()
+	val, err := cache.HLenCtx(ctx, key)
 	if err != nil {
 		cache.logger.Error("Redis HLen：", zap.String("key", key), zap.Error(err))
 		return 0
@@ -386,8 +354,7 @@ func (cache *redisCache) HLen(key string) int64 {
 
 // 保存
 func (cache *redisCache) HSet(key string, values ...any) int64 {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.HSet(ctx, ckey, values...).Result()
+	val, err := cache.HSetCtx(ctx, key, values...)
 	if err != nil {
 		cache.logger.Error("Redis HSet：", zap.String("key", key), zap.Any("value", values), zap.Error(err))
 		return 0
@@ -395,8 +362,7 @@ func (cache *redisCache) HSet(key string, values ...any) int64 {
 	return val
 }
 func (cache *redisCache) HIncrBy(key, field string, incr int64) int64 {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.HIncrBy(ctx, ckey, field, incr).Result()
+	val, err := cache.HIncrByCtx(ctx, key, field, incr)
 	if err != nil {
 		cache.logger.Error("Redis HIncrBy：", zap.String("key", key), zap.String("field", field), zap.Int64("incr", incr), zap.Error(err))
 		return 0
@@ -404,8 +370,7 @@ func (cache *redisCache) HIncrBy(key, field string, incr int64) int64 {
 	return val
 }
 func (cache *redisCache) HDel(key string, fields ...string) int64 {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.HDel(ctx, ckey, fields...).Result()
+	val, err := cache.HDelCtx(ctx, key, fields...)
 	if err != nil {
 		cache.logger.Error("Redis HDel：", zap.String("key", key), zap.String("field", strings.Join(fields, ";")), zap.Error(err))
 		return 0
@@ -414,7 +379,7 @@ func (cache *redisCache) HDel(key string, fields ...string) int64 {
 }
 
 func (cache *redisCache) FlushDB() bool {
-	_, err := cache.rdb.FlushDB(ctx).Result()
+	err := cache.FlushDBCtx(ctx)
 	if err != nil {
 		cache.logger.Error("Redis FlushDB：", zap.Error(err))
 		return false
@@ -423,8 +388,7 @@ func (cache *redisCache) FlushDB() bool {
 }
 
 func (cache *redisCache) Expire(key string, expiration time.Duration) bool {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.Expire(ctx, ckey, expiration).Result()
+	val, err := cache.ExpireCtx(ctx, key, expiration)
 	if err != nil {
 		cache.logger.Error("Redis Expire", zap.String("key", key), zap.Duration("exp", expiration), zap.Error(err))
 		return false
@@ -432,8 +396,7 @@ func (cache *redisCache) Expire(key string, expiration time.Duration) bool {
 	return val
 }
 func (cache *redisCache) PExpire(key string, expiration time.Duration) bool {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.PExpire(ctx, ckey, expiration).Result()
+	val, err := cache.PExpireCtx(ctx, key, expiration)
 	if err != nil {
 		cache.logger.Error("Redis PExpire", zap.String("key", key), zap.Duration("exp", expiration), zap.Error(err))
 		return false
@@ -441,8 +404,7 @@ func (cache *redisCache) PExpire(key string, expiration time.Duration) bool {
 	return val
 }
 func (cache *redisCache) ExpireAt(key string, tm time.Time) bool {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.ExpireAt(ctx, ckey, tm).Result()
+	val, err := cache.ExpireAtCtx(ctx, key, tm)
 	if err != nil {
 		cache.logger.Error("Redis ExpireAt", zap.String("key", key), zap.Time("tm", tm), zap.Error(err))
 		return false
@@ -450,8 +412,7 @@ func (cache *redisCache) ExpireAt(key string, tm time.Time) bool {
 	return val
 }
 func (cache *redisCache) PExpireAt(key string, tm time.Time) bool {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.PExpireAt(ctx, ckey, tm).Result()
+	val, err := cache.PExpireAtCtx(ctx, key, tm)
 	if err != nil {
 		cache.logger.Error("Redis PExpireAt", zap.String("key", key), zap.Time("tm", tm), zap.Error(err))
 		return false
@@ -460,8 +421,7 @@ func (cache *redisCache) PExpireAt(key string, tm time.Time) bool {
 }
 
 func (cache *redisCache) GetBit(key string, offset int64) int64 {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.GetBit(ctx, ckey, offset).Result()
+	val, err := cache.GetBitCtx(ctx, key, offset)
 	if err != nil {
 		cache.logger.Error("Redis GetBit", zap.String("key", key), zap.Int64("offset", offset), zap.Error(err))
 		return 0
@@ -469,8 +429,7 @@ func (cache *redisCache) GetBit(key string, offset int64) int64 {
 	return val
 }
 func (cache *redisCache) SetBit(key string, offset int64, val int) int64 {
-	ckey := cache.prefix + ":" + key
-	val64, err := cache.rdb.SetBit(ctx, ckey, offset, val).Result()
+	val64, err := cache.SetBitCtx(ctx, key, offset, val)
 	if err != nil {
 		cache.logger.Error("Redis SetBit", zap.String("key", key), zap.Int64("offset", offset), zap.Int("val", val), zap.Error(err))
 		return 0
@@ -478,8 +437,7 @@ func (cache *redisCache) SetBit(key string, offset int64, val int) int64 {
 	return val64
 }
 func (cache *redisCache) BitCount(key string, start, end int64) int64 {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.BitCount(ctx, ckey, &redis.BitCount{Start: start, End: end}).Result()
+	val, err := cache.BitCountCtx(ctx, key, start, end)
 	if err != nil {
 		cache.logger.Error("Redis BitCount", zap.String("key", key), zap.Int64("start", start), zap.Int64("end", end), zap.Int64("val", val), zap.Error(err))
 		return 0
@@ -488,8 +446,7 @@ func (cache *redisCache) BitCount(key string, start, end int64) int64 {
 }
 
 func (cache *redisCache) LLen(key string) int64 {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.LLen(ctx, ckey).Result()
+	val, err := cache.LLenCtx(ctx, key)
 	if err != nil {
 		cache.logger.Error("Redis LLen", zap.String("key", key), zap.Error(err))
 		return 0
@@ -497,8 +454,7 @@ func (cache *redisCache) LLen(key string) int64 {
 	return val
 }
 func (cache *redisCache) LPush(key string, values ...any) int64 {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.LPush(ctx, ckey, values...).Result()
+	val, err := cache.LPushCtx(ctx, key, values...)
 	if err != nil {
 		cache.logger.Error("Redis LPush", zap.String("key", key), zap.Error(err))
 		return 0
@@ -506,17 +462,17 @@ func (cache *redisCache) LPush(key string, values ...any) int64 {
 	return val
 }
 func (cache *redisCache) LPop(key string) []byte {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.LPop(ctx, ckey).Bytes()
+	val, err := cache.LPopCtx(ctx, key)
 	if err != nil {
-		cache.logger.Error("Redis LPop", zap.String("key", key), zap.Error(err))
+		if !errors.Is(err, ErrNotFound) {
+			cache.logger.Error("Redis LPop", zap.String("key", key), zap.Error(err))
+		}
 		return nil
 	}
 	return val
 }
 func (cache *redisCache) RPush(key string, values ...any) int64 {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.RPush(ctx, ckey, values...).Result()
+	val, err := cache.RPushCtx(ctx, key, values...)
 	if err != nil {
 		cache.logger.Error("Redis RPush", zap.String("key", key), zap.Error(err))
 		return 0
@@ -524,17 +480,17 @@ func (cache *redisCache) RPush(key string, values ...any) int64 {
 	return val
 }
 func (cache *redisCache) RPop(key string) []byte {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.RPop(ctx, ckey).Bytes()
+	val, err := cache.RPopCtx(ctx, key)
 	if err != nil {
-		cache.logger.Error("Redis RPop", zap.String("key", key), zap.Error(err))
+		if !errors.Is(err, ErrNotFound) {
+			cache.logger.Error("Redis RPop", zap.String("key", key), zap.Error(err))
+		}
 		return nil
 	}
 	return val
 }
 func (cache *redisCache) LRange(key string, start, stop int64) []string {
-	ckey := cache.prefix + ":" + key
-	val, err := cache.rdb.LRange(ctx, ckey, start, stop).Result()
+	val, err := cache.LRangeCtx(ctx, key, start, stop)
 	if err != nil {
 		cache.logger.Error("Redis LRange", zap.String("key", key), zap.Int64("start", start), zap.Int64("stop", stop), zap.Error(err))
 		return nil
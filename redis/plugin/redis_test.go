@@ -0,0 +1,22 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/livexy/plugin/cacher"
+
+	"github.com/alicebob/miniredis/v2"
+	"go.uber.org/zap"
+)
+
+// 基于 miniredis 的测试实例
+func newTestCache(t *testing.T) (*redisCache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	c, err := NewRedisCacheWithConfig(RedisConfig{CacheConfig: cacher.CacheConfig{Addr: []string{mr.Addr()}, Prefix: "test"}}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+	return c.(*redisCache), mr
+}
@@ -0,0 +1,178 @@
+package plugin
+
+import (
+	"context"
+	"iter"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/livexy/plugins/rediser"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 每次 SCAN 的建议返回数
+const scanCount = 200
+
+// PrefixOptions 按前缀批量操作选项
+type PrefixOptions = rediser.PrefixOptions
+
+// 需要遍历的节点，集群模式下为全部主节点
+func (cache *redisCache) scanNodes(ctx context.Context) ([]redis.Cmdable, error) {
+	cluster, ok := cache.rdb.(*redis.ClusterClient)
+	if !ok {
+		return []redis.Cmdable{cache.rdb}, nil
+	}
+	var mu sync.Mutex
+	nodes := []redis.Cmdable{}
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+		mu.Lock()
+		nodes = append(nodes, client)
+		mu.Unlock()
+		return nil
+	})
+	return nodes, err
+}
+
+// 转义 MATCH 模式中的通配符
+var globEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
+// ScanPrefix 逐个返回前缀下的完整键（含缓存前缀），集群模式下依次遍历每个主节点
+// 前缀按原样匹配，其中的 * ? [ ] \ 会被转义，避免匹配并误删其他键
+// 使用 SCAN 不阻塞 Redis，遍历期间新增或删除的键可能被遗漏或重复返回
+func (cache *redisCache) ScanPrefix(ctx context.Context, prefix string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		key, err := cache.getKey(ctx, prefix)
+		if err != nil {
+			yield("", err)
+			return
+		}
+		for k, serr := range cache.scan(ctx, globEscaper.Replace(key)+"*") {
+			if !yield(k, serr) {
+				return
+			}
+		}
+	}
+}
+
+// 按 MATCH 模式逐个返回完整键，出错时返回错误后结束
+func (cache *redisCache) scan(ctx context.Context, match string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		nodes, err := cache.scanNodes(ctx)
+		if err != nil {
+			yield("", err)
+			return
+		}
+		for _, node := range nodes {
+			var cursor uint64
+			for {
+				keys, cur, serr := node.Scan(ctx, cursor, match, scanCount).Result()
+				if serr != nil {
+					yield("", serr)
+					return
+				}
+				for _, key := range keys {
+					if !yield(key, nil) {
+						return
+					}
+				}
+				cursor = cur
+				if cur == 0 {
+					break
+				}
+			}
+		}
+	}
+}
+
+// 按批遍历前缀下的键
+func (cache *redisCache) eachPrefixBatch(ctx context.Context, prefix string, opt *PrefixOptions, fn func(keys []string) (int64, error)) (int64, error) {
+	o := PrefixOptions{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.Batch <= 0 {
+		o.Batch = 500
+	}
+	var done int64
+	batch := make([]string, 0, o.Batch)
+	flush := func() error {
+		n, err := fn(batch)
+		done += n
+		batch = batch[:0]
+		if err != nil {
+			return err
+		}
+		if o.Progress != nil {
+			o.Progress(done)
+		}
+		if o.Interval > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(o.Interval):
+			}
+		}
+		return nil
+	}
+	for key, err := range cache.ScanPrefix(ctx, prefix) {
+		if err != nil {
+			return done, err
+		}
+		batch = append(batch, key)
+		if len(batch) < o.Batch {
+			continue
+		}
+		ferr := flush()
+		if ferr != nil {
+			return done, ferr
+		}
+	}
+	if len(batch) > 0 {
+		ferr := flush()
+		return done, ferr
+	}
+	return done, nil
+}
+
+// DeleteByPrefix 按批 UNLINK 前缀下的所有键，返回删除数
+// 集群模式下每个键单独发送，由管道按节点合并，避免跨槽错误
+func (cache *redisCache) DeleteByPrefix(ctx context.Context, prefix string, opt *PrefixOptions) (int64, error) {
+	return cache.eachPrefixBatch(ctx, prefix, opt, func(keys []string) (int64, error) {
+		cmds := make([]*redis.IntCmd, 0, len(keys))
+		_, err := cache.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				cmds = append(cmds, pipe.Unlink(ctx, key))
+			}
+			return nil
+		})
+		var n int64
+		for _, cmd := range cmds {
+			n += cmd.Val()
+		}
+		cache.invalidate(ctx, keys...)
+		return n, err
+	})
+}
+
+// ExpireByPrefix 按批为前缀下的所有键设置过期时间，返回设置成功数
+func (cache *redisCache) ExpireByPrefix(ctx context.Context, prefix string, expiration time.Duration, opt *PrefixOptions) (int64, error) {
+	return cache.eachPrefixBatch(ctx, prefix, opt, func(keys []string) (int64, error) {
+		cmds := make([]*redis.BoolCmd, 0, len(keys))
+		_, err := cache.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				cmds = append(cmds, pipe.PExpire(ctx, key, expiration))
+			}
+			return nil
+		})
+		var n int64
+		for _, cmd := range cmds {
+			if cmd.Val() {
+				n++
+			}
+		}
+		cache.invalidate(ctx, keys...)
+		return n, err
+	})
+}
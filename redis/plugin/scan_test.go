@@ -0,0 +1,109 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 遍历前缀下的键并排序
+func scanKeys(t *testing.T, cache *redisCache, prefix string) []string {
+	t.Helper()
+	var keys []string
+	for key, err := range cache.ScanPrefix(context.Background(), prefix) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// 前缀中的通配符按原样匹配，GetPatternScan 仍按通配匹配
+func TestScanPrefixEscape(t *testing.T) {
+	cache, _ := newTestCache(t)
+	for _, key := range []string{"a*1", "a*2", "ab", "a?", "a[1]", "a1"} {
+		cache.Set(key, "v", 0)
+	}
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"a*", []string{"test:a*1", "test:a*2"}},
+		{"a?", []string{"test:a?"}},
+		{"a[1", []string{"test:a[1]"}},
+		{"a[1]", []string{"test:a[1]"}},
+		{"a", []string{"test:a*1", "test:a*2", "test:a1", "test:a?", "test:a[1]", "test:ab"}},
+	}
+	for _, tt := range tests {
+		if keys := scanKeys(t, cache, tt.prefix); !slices.Equal(keys, tt.want) {
+			t.Errorf("ScanPrefix(%q) = %v, want %v", tt.prefix, keys, tt.want)
+		}
+	}
+	keys := cache.GetPatternScan("a[1*]")
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"test:a*1", "test:a*2", "test:a1"}) {
+		t.Fatalf("GetPatternScan = %v", keys)
+	}
+
+	n, err := cache.DeleteByPrefix(context.Background(), "a*", nil)
+	if err != nil || n != 2 {
+		t.Fatalf("DeleteByPrefix = %d, %v", n, err)
+	}
+	if cache.Exists("ab", "a1", "a?", "a[1]") != 4 {
+		t.Fatal("unrelated keys deleted")
+	}
+}
+
+func TestPrefixBatch(t *testing.T) {
+	cache, mr := newTestCache(t)
+	ctx := context.Background()
+	for _, key := range []string{"p:1", "p:2", "p:3", "p:4", "p:5", "q:1"} {
+		cache.Set(key, "v", 0)
+	}
+	var progress []int64
+	opt := &PrefixOptions{Batch: 2, Progress: func(done int64) { progress = append(progress, done) }}
+	n, err := cache.ExpireByPrefix(ctx, "p:", time.Minute, opt)
+	if err != nil || n != 5 {
+		t.Fatalf("ExpireByPrefix = %d, %v", n, err)
+	}
+	if !slices.Equal(progress, []int64{2, 4, 5}) {
+		t.Fatalf("progress = %v", progress)
+	}
+	if mr.TTL(cache.stableKey("p:1")) != time.Minute || mr.TTL(cache.stableKey("q:1")) != 0 {
+		t.Fatal("ttl not set")
+	}
+
+	n, err = cache.DeleteByPrefix(ctx, "p:", &PrefixOptions{Batch: 2})
+	if err != nil || n != 5 {
+		t.Fatalf("DeleteByPrefix = %d, %v", n, err)
+	}
+	if keys := scanKeys(t, cache, ""); !slices.Equal(keys, []string{"test:q:1"}) {
+		t.Fatalf("keys = %v", keys)
+	}
+
+	// 上下文取消时返回取消错误
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	cache.Set("p:1", "v", 0)
+	if _, err = cache.DeleteByPrefix(cancelCtx, "p:", &PrefixOptions{Interval: time.Hour}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+// 命名空间只遍历自身的键
+func TestScanPrefixNamespace(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ns := cache.Namespace("ns", nil).(*redisCache)
+	cache.Set("k1", "v", 0)
+	ns.Set("k1", "v", 0)
+	ns.Set("k2", "v", 0)
+	keys := scanKeys(t, ns, "k")
+	if len(keys) != 2 || slices.Contains(keys, "test:k1") || !strings.HasSuffix(keys[0], ":k1") || !strings.HasSuffix(keys[1], ":k2") {
+		t.Fatalf("keys = %v", keys)
+	}
+}
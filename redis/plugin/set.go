@@ -0,0 +1,73 @@
+package plugin
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+func (cache *redisCache) SAddCtx(ctx context.Context, key string, members ...any) (int64, error) {
+	return cache.rdb.SAdd(ctx, cache.stableKey(key), members...).Result()
+}
+func (cache *redisCache) SRemCtx(ctx context.Context, key string, members ...any) (int64, error) {
+	return cache.rdb.SRem(ctx, cache.stableKey(key), members...).Result()
+}
+func (cache *redisCache) SMembersCtx(ctx context.Context, key string) ([]string, error) {
+	return cache.rdb.SMembers(ctx, cache.stableKey(key)).Result()
+}
+func (cache *redisCache) SIsMemberCtx(ctx context.Context, key string, member any) (bool, error) {
+	return cache.rdb.SIsMember(ctx, cache.stableKey(key), member).Result()
+}
+func (cache *redisCache) SCardCtx(ctx context.Context, key string) (int64, error) {
+	return cache.rdb.SCard(ctx, cache.stableKey(key)).Result()
+}
+
+// 添加集合成员
+func (cache *redisCache) SAdd(key string, members ...any) int64 {
+	val, err := cache.SAddCtx(ctx, key, members...)
+	if err != nil {
+		cache.logger.Error("Redis SAdd：", zap.String("key", key), zap.Any("members", members), zap.Error(err))
+		return 0
+	}
+	return val
+}
+
+// 删除集合成员
+func (cache *redisCache) SRem(key string, members ...any) int64 {
+	val, err := cache.SRemCtx(ctx, key, members...)
+	if err != nil {
+		cache.logger.Error("Redis SRem：", zap.String("key", key), zap.Any("members", members), zap.Error(err))
+		return 0
+	}
+	return val
+}
+
+// 全部集合成员
+func (cache *redisCache) SMembers(key string) []string {
+	val, err := cache.SMembersCtx(ctx, key)
+	if err != nil {
+		cache.logger.Error("Redis SMembers：", zap.String("key", key), zap.Error(err))
+		return nil
+	}
+	return val
+}
+
+// 是否集合成员
+func (cache *redisCache) SIsMember(key string, member any) bool {
+	val, err := cache.SIsMemberCtx(ctx, key, member)
+	if err != nil {
+		cache.logger.Error("Redis SIsMember：", zap.String("key", key), zap.Any("member", member), zap.Error(err))
+		return false
+	}
+	return val
+}
+
+// 集合成员数
+func (cache *redisCache) SCard(key string) int64 {
+	val, err := cache.SCardCtx(ctx, key)
+	if err != nil {
+		cache.logger.Error("Redis SCard：", zap.String("key", key), zap.Error(err))
+		return 0
+	}
+	return val
+}
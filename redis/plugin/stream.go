@@ -0,0 +1,174 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/livexy/plugins/rediser"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// XMessage 流消息
+type XMessage = rediser.XMessage
+
+// XPendingExt 待确认消息详情
+type XPendingExt = rediser.XPendingExt
+
+// 追加消息，maxLen 大于 0 时近似裁剪到该长度，返回消息 ID
+func (cache *redisCache) XAddCtx(ctx context.Context, key string, maxLen int64, values map[string]any) (string, error) {
+	return cache.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: cache.stableKey(key), MaxLen: maxLen, Approx: maxLen > 0, Values: values,
+	}).Result()
+}
+func (cache *redisCache) XLenCtx(ctx context.Context, key string) (int64, error) {
+	return cache.rdb.XLen(ctx, cache.stableKey(key)).Result()
+}
+func (cache *redisCache) XDelCtx(ctx context.Context, key string, ids ...string) (int64, error) {
+	return cache.rdb.XDel(ctx, cache.stableKey(key), ids...).Result()
+}
+
+// 读取 id 之后的消息，block 大于 0 时阻塞等待，超时返回空
+func (cache *redisCache) XReadCtx(ctx context.Context, key, id string, count int64, block time.Duration) ([]XMessage, error) {
+	args := &redis.XReadArgs{Streams: []string{cache.stableKey(key), id}, Count: count, Block: -1}
+	if block > 0 {
+		args.Block = block
+	}
+	streams, err := cache.rdb.XRead(ctx, args).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil || len(streams) == 0 {
+		return nil, err
+	}
+	return streams[0].Messages, nil
+}
+
+// 创建消费组，流不存在时自动创建，消费组已存在时忽略
+func (cache *redisCache) XGroupCreateCtx(ctx context.Context, key, group, start string) error {
+	err := cache.rdb.XGroupCreateMkStream(ctx, cache.stableKey(key), group, start).Err()
+	if isBusyGroup(err) {
+		return nil
+	}
+	return err
+}
+
+// 消费组已存在
+func isBusyGroup(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP")
+}
+
+// 以消费组方式读取，id 为 ">" 时读取新消息，为 "0" 时读取本消费者未确认的消息
+func (cache *redisCache) XReadGroupCtx(ctx context.Context, key, group, consumer, id string, count int64, block time.Duration) ([]XMessage, error) {
+	args := &redis.XReadGroupArgs{
+		Group: group, Consumer: consumer,
+		Streams: []string{cache.stableKey(key), id}, Count: count, Block: -1,
+	}
+	if block > 0 {
+		args.Block = block
+	}
+	streams, err := cache.rdb.XReadGroup(ctx, args).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil || len(streams) == 0 {
+		return nil, err
+	}
+	return streams[0].Messages, nil
+}
+func (cache *redisCache) XAckCtx(ctx context.Context, key, group string, ids ...string) (int64, error) {
+	return cache.rdb.XAck(ctx, cache.stableKey(key), group, ids...).Result()
+}
+
+// 待确认消息，idle 大于 0 时只返回空闲超过该时长的消息
+func (cache *redisCache) XPendingCtx(ctx context.Context, key, group string, count int64, idle time.Duration) ([]XPendingExt, error) {
+	return cache.rdb.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: cache.stableKey(key), Group: group, Idle: idle,
+		Start: "-", End: "+", Count: count,
+	}).Result()
+}
+
+// 将空闲超过 minIdle 的消息转移给 consumer
+func (cache *redisCache) XClaimCtx(ctx context.Context, key, group, consumer string, minIdle time.Duration, ids ...string) ([]XMessage, error) {
+	return cache.rdb.XClaim(ctx, &redis.XClaimArgs{
+		Stream: cache.stableKey(key), Group: group, Consumer: consumer,
+		MinIdle: minIdle, Messages: ids,
+	}).Result()
+}
+
+// 追加消息
+func (cache *redisCache) XAdd(key string, maxLen int64, values map[string]any) string {
+	val, err := cache.XAddCtx(ctx, key, maxLen, values)
+	if err != nil {
+		cache.logger.Error("Redis XAdd：", zap.String("key", key), zap.Any("values", values), zap.Error(err))
+		return ""
+	}
+	return val
+}
+func (cache *redisCache) XLen(key string) int64 {
+	val, err := cache.XLenCtx(ctx, key)
+	if err != nil {
+		cache.logger.Error("Redis XLen：", zap.String("key", key), zap.Error(err))
+		return 0
+	}
+	return val
+}
+func (cache *redisCache) XDel(key string, ids ...string) int64 {
+	val, err := cache.XDelCtx(ctx, key, ids...)
+	if err != nil {
+		cache.logger.Error("Redis XDel：", zap.String("key", key), zap.Strings("ids", ids), zap.Error(err))
+		return 0
+	}
+	return val
+}
+func (cache *redisCache) XRead(key, id string, count int64, block time.Duration) []XMessage {
+	val, err := cache.XReadCtx(ctx, key, id, count, block)
+	if err != nil {
+		cache.logger.Error("Redis XRead：", zap.String("key", key), zap.String("id", id), zap.Error(err))
+		return nil
+	}
+	return val
+}
+func (cache *redisCache) XGroupCreate(key, group, start string) bool {
+	err := cache.XGroupCreateCtx(ctx, key, group, start)
+	if err != nil {
+		cache.logger.Error("Redis XGroupCreate：", zap.String("key", key), zap.String("group", group), zap.Error(err))
+		return false
+	}
+	return true
+}
+func (cache *redisCache) XReadGroup(key, group, consumer, id string, count int64, block time.Duration) []XMessage {
+	val, err := cache.XReadGroupCtx(ctx, key, group, consumer, id, count, block)
+	if err != nil {
+		cache.logger.Error("Redis XReadGroup：", zap.String("key", key), zap.String("group", group), zap.String("consumer", consumer), zap.Error(err))
+		return nil
+	}
+	return val
+}
+func (cache *redisCache) XAck(key, group string, ids ...string) int64 {
+	val, err := cache.XAckCtx(ctx, key, group, ids...)
+	if err != nil {
+		cache.logger.Error("Redis XAck：", zap.String("key", key), zap.String("group", group), zap.Strings("ids", ids), zap.Error(err))
+		return 0
+	}
+	return val
+}
+func (cache *redisCache) XPending(key, group string, count int64, idle time.Duration) []XPendingExt {
+	val, err := cache.XPendingCtx(ctx, key, group, count, idle)
+	if err != nil {
+		cache.logger.Error("Redis XPending：", zap.String("key", key), zap.String("group", group), zap.Error(err))
+		return nil
+	}
+	return val
+}
+func (cache *redisCache) XClaim(key, group, consumer string, minIdle time.Duration, ids ...string) []XMessage {
+	val, err := cache.XClaimCtx(ctx, key, group, consumer, minIdle, ids...)
+	if err != nil {
+		cache.logger.Error("Redis XClaim：", zap.String("key", key), zap.String("group", group), zap.Strings("ids", ids), zap.Error(err))
+		return nil
+	}
+	return val
+}
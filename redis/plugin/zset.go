@@ -0,0 +1,163 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/livexy/plugins/rediser"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Z 有序集合成员
+type Z = rediser.Z
+
+func (cache *redisCache) ZAddCtx(ctx context.Context, key string, members ...Z) (int64, error) {
+	return cache.rdb.ZAdd(ctx, cache.stableKey(key), members...).Result()
+}
+func (cache *redisCache) ZIncrByCtx(ctx context.Context, key string, incr float64, member string) (float64, error) {
+	return cache.rdb.ZIncrBy(ctx, cache.stableKey(key), incr, member).Result()
+}
+
+// 按排名升序取成员
+func (cache *redisCache) ZRangeCtx(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return cache.rdb.ZRange(ctx, cache.stableKey(key), start, stop).Result()
+}
+
+// 按排名降序取成员及分数
+func (cache *redisCache) ZRevRangeWithScoresCtx(ctx context.Context, key string, start, stop int64) ([]Z, error) {
+	return cache.rdb.ZRevRangeWithScores(ctx, cache.stableKey(key), start, stop).Result()
+}
+
+// 按分数区间取成员，min/max 支持 "-inf"、"+inf" 及 "(" 开区间，count 为 0 时不分页
+func (cache *redisCache) ZRangeByScoreCtx(ctx context.Context, key, min, max string, offset, count int64) ([]string, error) {
+	return cache.rdb.ZRangeByScore(ctx, cache.stableKey(key), &redis.ZRangeBy{Min: min, Max: max, Offset: offset, Count: count}).Result()
+}
+
+// 升序排名，成员不存在时返回 ErrNotFound
+func (cache *redisCache) ZRankCtx(ctx context.Context, key, member string) (int64, error) {
+	val, err := cache.rdb.ZRank(ctx, cache.stableKey(key), member).Result()
+	if err != nil {
+		return 0, notFound(err)
+	}
+	return val, nil
+}
+
+// 降序排名，成员不存在时返回 ErrNotFound
+func (cache *redisCache) ZRevRankCtx(ctx context.Context, key, member string) (int64, error) {
+	val, err := cache.rdb.ZRevRank(ctx, cache.stableKey(key), member).Result()
+	if err != nil {
+		return 0, notFound(err)
+	}
+	return val, nil
+}
+
+// 成员分数，成员不存在时返回 ErrNotFound
+func (cache *redisCache) ZScoreCtx(ctx context.Context, key, member string) (float64, error) {
+	val, err := cache.rdb.ZScore(ctx, cache.stableKey(key), member).Result()
+	if err != nil {
+		return 0, notFound(err)
+	}
+	return val, nil
+}
+func (cache *redisCache) ZRemCtx(ctx context.Context, key string, members ...any) (int64, error) {
+	return cache.rdb.ZRem(ctx, cache.stableKey(key), members...).Result()
+}
+func (cache *redisCache) ZCardCtx(ctx context.Context, key string) (int64, error) {
+	return cache.rdb.ZCard(ctx, cache.stableKey(key)).Result()
+}
+
+// 添加有序集合成员
+func (cache *redisCache) ZAdd(key string, members ...Z) int64 {
+	val, err := cache.ZAddCtx(ctx, key, members...)
+	if err != nil {
+		cache.logger.Error("Redis ZAdd：", zap.String("key", key), zap.Any("members", members), zap.Error(err))
+		return 0
+	}
+	return val
+}
+
+// 成员分数累加
+func (cache *redisCache) ZIncrBy(key string, incr float64, member string) float64 {
+	val, err := cache.ZIncrByCtx(ctx, key, incr, member)
+	if err != nil {
+		cache.logger.Error("Redis ZIncrBy：", zap.String("key", key), zap.Float64("incr", incr), zap.String("member", member), zap.Error(err))
+		return 0
+	}
+	return val
+}
+func (cache *redisCache) ZRange(key string, start, stop int64) []string {
+	val, err := cache.ZRangeCtx(ctx, key, start, stop)
+	if err != nil {
+		cache.logger.Error("Redis ZRange：", zap.String("key", key), zap.Int64("start", start), zap.Int64("stop", stop), zap.Error(err))
+		return nil
+	}
+	return val
+}
+func (cache *redisCache) ZRevRangeWithScores(key string, start, stop int64) []Z {
+	val, err := cache.ZRevRangeWithScoresCtx(ctx, key, start, stop)
+	if err != nil {
+		cache.logger.Error("Redis ZRevRangeWithScores：", zap.String("key", key), zap.Int64("start", start), zap.Int64("stop", stop), zap.Error(err))
+		return nil
+	}
+	return val
+}
+func (cache *redisCache) ZRangeByScore(key, min, max string, offset, count int64) []string {
+	val, err := cache.ZRangeByScoreCtx(ctx, key, min, max, offset, count)
+	if err != nil {
+		cache.logger.Error("Redis ZRangeByScore：", zap.String("key", key), zap.String("min", min), zap.String("max", max), zap.Error(err))
+		return nil
+	}
+	return val
+}
+
+// 升序排名，成员不存在时返回 -1
+func (cache *redisCache) ZRank(key, member string) int64 {
+	val, err := cache.ZRankCtx(ctx, key, member)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			cache.logger.Error("Redis ZRank：", zap.String("key", key), zap.String("member", member), zap.Error(err))
+		}
+		return -1
+	}
+	return val
+}
+
+// 降序排名，成员不存在时返回 -1
+func (cache *redisCache) ZRevRank(key, member string) int64 {
+	val, err := cache.ZRevRankCtx(ctx, key, member)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			cache.logger.Error("Redis ZRevRank：", zap.String("key", key), zap.String("member", member), zap.Error(err))
+		}
+		return -1
+	}
+	return val
+}
+func (cache *redisCache) ZScore(key, member string) float64 {
+	val, err := cache.ZScoreCtx(ctx, key, member)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			cache.logger.Error("Redis ZScore：", zap.String("key", key), zap.String("member", member), zap.Error(err))
+		}
+		return 0
+	}
+	return val
+}
+func (cache *redisCache) ZRem(key string, members ...any) int64 {
+	val, err := cache.ZRemCtx(ctx, key, members...)
+	if err != nil {
+		cache.logger.Error("Redis ZRem：", zap.String("key", key), zap.Any("members", members), zap.Error(err))
+		return 0
+	}
+	return val
+}
+func (cache *redisCache) ZCard(key string) int64 {
+	val, err := cache.ZCardCtx(ctx, key)
+	if err != nil {
+		cache.logger.Error("Redis ZCard：", zap.String("key", key), zap.Error(err))
+		return 0
+	}
+	return val
+}
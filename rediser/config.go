@@ -0,0 +1,42 @@
+package rediser
+
+import (
+	"time"
+
+	"github.com/livexy/plugin/cacher"
+)
+
+// RedisConfig 扩展的 Redis 配置，兼容 cacher.CacheConfig
+// Addr 为一个地址时使用单机，多个地址时使用集群，设置 MasterName 时 Addr 为哨兵地址
+type RedisConfig struct {
+	cacher.CacheConfig `yaml:",inline"`
+
+	Username         string     `yaml:"username"`         // ACL 用户名
+	MasterName       string     `yaml:"masterName"`       // 哨兵主节点名称
+	SentinelUsername string     `yaml:"sentinelUsername"` // 哨兵 ACL 用户名
+	SentinelPassword string     `yaml:"sentinelPassword"` // 哨兵密码
+	TLS              *TLSConfig `yaml:"tls"`
+
+	DialTimeout     time.Duration `yaml:"dialTimeout"`
+	ReadTimeout     time.Duration `yaml:"readTimeout"`
+	WriteTimeout    time.Duration `yaml:"writeTimeout"`
+	PoolTimeout     time.Duration `yaml:"poolTimeout"`
+	MaxRetries      int           `yaml:"maxRetries"` // -1 禁用重试
+	MaxIdleConns    int           `yaml:"maxIdleConns"`
+	MaxActiveConns  int           `yaml:"maxActiveConns"`
+	ConnMaxIdleTime time.Duration `yaml:"connMaxIdleTime"`
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime"`
+
+	ReadOnly       bool `yaml:"readOnly"`       // 集群/哨兵模式下只读命令发往副本，写命令仍发往主节点
+	RouteByLatency bool `yaml:"routeByLatency"` // 按延迟选择节点读取，隐含 ReadOnly
+	RouteRandomly  bool `yaml:"routeRandomly"`  // 随机选择节点读取，隐含 ReadOnly
+}
+
+// TLSConfig TLS 连接配置
+type TLSConfig struct {
+	CAFile             string `yaml:"caFile"`   // 私有 CA 证书
+	CertFile           string `yaml:"certFile"` // 客户端证书
+	KeyFile            string `yaml:"keyFile"`  // 客户端私钥
+	ServerName         string `yaml:"serverName"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify"`
+}
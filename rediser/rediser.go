@@ -0,0 +1,205 @@
+// Package rediser Redis 缓存插件的扩展接口与配置
+// 宿主通过该包引用扩展能力，无需依赖插件包本身；插件入口仍返回 cacher.Cacher，需要扩展能力时断言为 RedisCacher
+package rediser
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"time"
+
+	"github.com/livexy/plugin/cacher"
+	"github.com/livexy/plugin/streamer"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrNotFound 键或字段不存在
+	ErrNotFound = errors.New("缓存不存在")
+	// ErrTxFailed WATCH 的键在事务提交前被修改，事务未执行
+	ErrTxFailed = redis.TxFailedErr
+	// ErrLockNotObtained 等待超时仍未获得锁
+	ErrLockNotObtained = errors.New("获取锁失败")
+	// ErrLockNotHeld 锁已过期或被他人持有
+	ErrLockNotHeld = errors.New("锁未持有")
+	// ErrObjectFormat 缓存内容不是 SetObject 写入的格式
+	ErrObjectFormat = errors.New("缓存对象格式错误")
+	// ErrRateArgs 限流参数无效
+	ErrRateArgs = errors.New("限流参数无效")
+	// ErrNotNamespace 根实例不支持按命名空间失效
+	ErrNotNamespace = errors.New("当前实例不是命名空间视图")
+)
+
+// CtxCacher 在 cacher.Cacher 基础上提供携带 context 并返回 error 的方法
+// 原有方法均为这些方法的包装，使用 context.Background() 并吞掉错误
+type CtxCacher interface {
+	cacher.Cacher
+
+	SetCtx(ctx context.Context, key string, value any, expiration time.Duration) error
+	SetNXCtx(ctx context.Context, key string, value any, expiration time.Duration) (bool, error)
+	SetXXCtx(ctx context.Context, key string, value any, expiration time.Duration) (bool, error)
+	IncrCtx(ctx context.Context, key string) (int64, error)
+	IncrByCtx(ctx context.Context, key string, val int64) (int64, error)
+	DecrCtx(ctx context.Context, key string) (int64, error)
+	DecrByCtx(ctx context.Context, key string, val int64) (int64, error)
+	ExistsCtx(ctx context.Context, keys ...string) (int64, error)
+	GetCtx(ctx context.Context, key string) (string, bool, error)
+	MGetCtx(ctx context.Context, keys ...string) ([]any, error)
+	GetBytesCtx(ctx context.Context, key string) ([]byte, bool, error)
+	GetIntCtx(ctx context.Context, key string) (int, error)
+	GetInt64Ctx(ctx context.Context, key string) (int64, error)
+	GetSetCtx(ctx context.Context, key string, value any) (string, error)
+	GetPatternKeysCtx(ctx context.Context, prefix string) ([]string, error)
+	GetPatternScanCtx(ctx context.Context, prefix string) ([]string, error)
+	DeleteCtx(ctx context.Context, keys ...string) error
+	UnlinkCtx(ctx context.Context, keys ...string) error
+	DeleteKeysCtx(ctx context.Context, keys ...string) error
+	UnlinkKeysCtx(ctx context.Context, keys ...string) error
+	LockStartCtx(ctx context.Context, key string, args ...int) (bool, error)
+	LockEndCtx(ctx context.Context, key string) error
+	HExistsCtx(ctx context.Context, key, field string) (bool, error)
+	HGetCtx(ctx context.Context, key, field string) (string, bool, error)
+	HGetBytesCtx(ctx context.Context, key, field string) ([]byte, bool, error)
+	HGetInt64Ctx(ctx context.Context, key, field string) (int64, error)
+	HGetAllCtx(ctx context.Context, key string) (map[string]string, error)
+	HKeysCtx(ctx context.Context, key string) ([]string, error)
+	HSetCtx(ctx context.Context, key string, values ...any) (int64, error)
+	HDelCtx(ctx context.Context, key string, fields ...string) (int64, error)
+	HLenCtx(ctx context.Context, key string) (int64, error)
+	HIncrByCtx(ctx context.Context, key, field string, incr int64) (int64, error)
+	FlushDBCtx(ctx context.Context) error
+	ExpireCtx(ctx context.Context, key string, expiration time.Duration) (bool, error)
+	PExpireCtx(ctx context.Context, key string, expiration time.Duration) (bool, error)
+	ExpireAtCtx(ctx context.Context, key string, tm time.Time) (bool, error)
+	PExpireAtCtx(ctx context.Context, key string, tm time.Time) (bool, error)
+	GetBitCtx(ctx context.Context, key string, offset int64) (int64, error)
+	SetBitCtx(ctx context.Context, key string, offset int64, val int) (int64, error)
+	BitCountCtx(ctx context.Context, key string, start, end int64) (int64, error)
+	LLenCtx(ctx context.Context, key string) (int64, error)
+	LPushCtx(ctx context.Context, key string, values ...any) (int64, error)
+	LPopCtx(ctx context.Context, key string) ([]byte, error)
+	RPushCtx(ctx context.Context, key string, values ...any) (int64, error)
+	RPopCtx(ctx context.Context, key string) ([]byte, error)
+	LRangeCtx(ctx context.Context, key string, start, stop int64) ([]string, error)
+}
+
+// RedisCacher Redis 缓存插件提供的完整能力
+// 插件入口仍返回 cacher.Cacher，需要扩展能力时断言为该接口
+type RedisCacher interface {
+	CtxCacher
+
+	// 带持有者令牌的分布式锁
+	ObtainLock(ctx context.Context, key string, opt *LockOptions) (Lock, error)
+
+	// 对象缓存
+	UseStreamer(s streamer.Streamer, threshold int)
+	SetObject(key string, v any, expiration time.Duration) bool
+	GetObject(key string, v any) bool
+	SetObjectCtx(ctx context.Context, key string, v any, expiration time.Duration) error
+	GetObjectCtx(ctx context.Context, key string, v any) (bool, error)
+
+	// 缓存回源
+	GetOrLoad(key string, expiration time.Duration, v any, loader func() (any, error), opt *LoadOptions) error
+	GetOrLoadCtx(ctx context.Context, key string, expiration time.Duration, v any, loader func(ctx context.Context) (any, error), opt *LoadOptions) error
+
+	// 进程内一级缓存
+	EnableLocal(opt LocalOptions) error
+	Stats() CacheStats
+
+	// 发布订阅
+	Publish(channel string, msg any) int64
+	PublishCtx(ctx context.Context, channel string, msg any) (int64, error)
+	Subscribe(ctx context.Context, handler func(Message), channels ...string) (Subscription, error)
+	PSubscribe(ctx context.Context, handler func(Message), patterns ...string) (Subscription, error)
+	SubscribeExpired(ctx context.Context, handler func(key string)) (Subscription, error)
+
+	// 指标与追踪
+	UseMetrics(metrics Metrics, tracer Tracer, interval time.Duration)
+	PoolStats() PoolStats
+
+	// 批量命令与事务
+	Pipeline() Batch
+	TxPipeline() Batch
+	Watch(ctx context.Context, fn func(tx Tx) error, keys ...string) error
+
+	// 集合
+	SAdd(key string, members ...any) int64
+	SRem(key string, members ...any) int64
+	SMembers(key string) []string
+	SIsMember(key string, member any) bool
+	SCard(key string) int64
+	SAddCtx(ctx context.Context, key string, members ...any) (int64, error)
+	SRemCtx(ctx context.Context, key string, members ...any) (int64, error)
+	SMembersCtx(ctx context.Context, key string) ([]string, error)
+	SIsMemberCtx(ctx context.Context, key string, member any) (bool, error)
+	SCardCtx(ctx context.Context, key string) (int64, error)
+
+	// 有序集合
+	ZAdd(key string, members ...Z) int64
+	ZIncrBy(key string, incr float64, member string) float64
+	ZRange(key string, start, stop int64) []string
+	ZRevRangeWithScores(key string, start, stop int64) []Z
+	ZRangeByScore(key, min, max string, offset, count int64) []string
+	ZRank(key, member string) int64
+	ZRevRank(key, member string) int64
+	ZScore(key, member string) float64
+	ZRem(key string, members ...any) int64
+	ZCard(key string) int64
+	ZAddCtx(ctx context.Context, key string, members ...Z) (int64, error)
+	ZIncrByCtx(ctx context.Context, key string, incr float64, member string) (float64, error)
+	ZRangeCtx(ctx context.Context, key string, start, stop int64) ([]string, error)
+	ZRevRangeWithScoresCtx(ctx context.Context, key string, start, stop int64) ([]Z, error)
+	ZRangeByScoreCtx(ctx context.Context, key, min, max string, offset, count int64) ([]string, error)
+	ZRankCtx(ctx context.Context, key, member string) (int64, error)
+	ZRevRankCtx(ctx context.Context, key, member string) (int64, error)
+	ZScoreCtx(ctx context.Context, key, member string) (float64, error)
+	ZRemCtx(ctx context.Context, key string, members ...any) (int64, error)
+	ZCardCtx(ctx context.Context, key string) (int64, error)
+
+	// 流
+	XAdd(key string, maxLen int64, values map[string]any) string
+	XLen(key string) int64
+	XDel(key string, ids ...string) int64
+	XRead(key, id string, count int64, block time.Duration) []XMessage
+	XGroupCreate(key, group, start string) bool
+	XReadGroup(key, group, consumer, id string, count int64, block time.Duration) []XMessage
+	XAck(key, group string, ids ...string) int64
+	XPending(key, group string, count int64, idle time.Duration) []XPendingExt
+	XClaim(key, group, consumer string, minIdle time.Duration, ids ...string) []XMessage
+	XAddCtx(ctx context.Context, key string, maxLen int64, values map[string]any) (string, error)
+	XLenCtx(ctx context.Context, key string) (int64, error)
+	XDelCtx(ctx context.Context, key string, ids ...string) (int64, error)
+	XReadCtx(ctx context.Context, key, id string, count int64, block time.Duration) ([]XMessage, error)
+	XGroupCreateCtx(ctx context.Context, key, group, start string) error
+	XReadGroupCtx(ctx context.Context, key, group, consumer, id string, count int64, block time.Duration) ([]XMessage, error)
+	XAckCtx(ctx context.Context, key, group string, ids ...string) (int64, error)
+	XPendingCtx(ctx context.Context, key, group string, count int64, idle time.Duration) ([]XPendingExt, error)
+	XClaimCtx(ctx context.Context, key, group, consumer string, minIdle time.Duration, ids ...string) ([]XMessage, error)
+
+	// 布隆过滤器与基数统计
+	NewBloom(name string, opt *BloomOptions) Bloom
+	PFAdd(key string, els ...any) int64
+	PFCount(keys ...string) int64
+	PFMerge(dest string, keys ...string) bool
+	PFAddCtx(ctx context.Context, key string, els ...any) (int64, error)
+	PFCountCtx(ctx context.Context, keys ...string) (int64, error)
+	PFMergeCtx(ctx context.Context, dest string, keys ...string) error
+
+	// 可靠任务队列
+	NewQueue(ctx context.Context, name string, opt *QueueOptions) (Queue, error)
+
+	// 限流
+	AllowFixedWindow(ctx context.Context, key string, limit int64, window time.Duration, cost int64) (RateResult, error)
+	AllowSlidingWindow(ctx context.Context, key string, limit int64, window time.Duration) (RateResult, error)
+	AllowTokenBucket(ctx context.Context, key string, rate int64, period time.Duration, burst, cost int64) (RateResult, error)
+
+	// 命名空间
+	Namespace(name string, opt *NamespaceOptions) RedisCacher
+	InvalidateNamespace(ctx context.Context) error
+
+	// 按前缀遍历与批量操作，前缀按原样匹配，其中的通配符会被转义
+	ScanPrefix(ctx context.Context, prefix string) iter.Seq2[string, error]
+	DeleteByPrefix(ctx context.Context, prefix string, opt *PrefixOptions) (int64, error)
+	ExpireByPrefix(ctx context.Context, prefix string, expiration time.Duration, opt *PrefixOptions) (int64, error)
+}
@@ -0,0 +1,248 @@
+package rediser
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Z 有序集合成员
+type Z = redis.Z
+
+// XMessage 流消息
+type XMessage = redis.XMessage
+
+// XPendingExt 待确认消息详情
+type XPendingExt = redis.XPendingExt
+
+// PoolStats 连接池统计（命中、未命中、超时、空闲与总连接数）
+type PoolStats = redis.PoolStats
+
+// Metrics 指标接口，由调用方对接 Prometheus 等系统
+// 方法在命令执行路径上同步调用，实现需并发安全且不能阻塞
+type Metrics interface {
+	// 单条命令或整个管道（name 为 pipeline）的耗时，err 不含键不存在
+	ObserveCommand(name string, duration time.Duration, err error)
+	// get、hget、mget、hgetall 等读命令的命中情况，与 CacheStats 的 Redis 命中同源
+	ObserveHit(name string, hit bool)
+	// 定期上报的连接池统计
+	ObservePool(stats PoolStats)
+}
+
+// Tracer 可选的命令级追踪，Start 返回的 end 在命令结束时调用
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, func(err error))
+}
+
+// LockOptions 分布式锁选项
+type LockOptions struct {
+	TTL         time.Duration // 锁过期时间，默认 10 秒，不足 1 毫秒按 1 毫秒计
+	WaitTimeout time.Duration // 阻塞等待时间，0 表示只尝试一次
+	RetryMin    time.Duration // 重试最小间隔，默认 10ms
+	RetryMax    time.Duration // 重试最大间隔，默认 500ms
+	AutoRenew   bool          // 持有期间由看门狗按 TTL/3 自动续期
+}
+
+// Lock 带持有者令牌的分布式锁
+// 与 LockStart/LockEnd 使用不同的键，二者互不感知
+type Lock interface {
+	// 锁的完整键名
+	Key() string
+	// 持有者令牌
+	Token() string
+	// 单调递增的 fencing token，写入下游存储时用于拒绝过期持有者
+	Fence() int64
+	// 看门狗发现锁丢失时关闭
+	Lost() <-chan struct{}
+	// 续期，ttl 小于等于 0 时使用加锁时的 TTL，锁已不属于自己时返回 ErrLockNotHeld
+	Refresh(ctx context.Context, ttl time.Duration) error
+	// 剩余时间，锁已不属于自己时返回 ErrLockNotHeld
+	TTL(ctx context.Context) (time.Duration, error)
+	// 释放锁，仅删除自己持有的锁，已丢失时返回 ErrLockNotHeld
+	Release(ctx context.Context) error
+}
+
+// LoadOptions 缓存回源选项
+type LoadOptions struct {
+	NegativeTTL time.Duration // loader 返回 ErrNotFound 时缓存空标记的时长，0 不缓存
+	Jitter      float64       // TTL 随机抖动比例，如 0.1 表示 ±10%，避免集中过期
+	LockTTL     time.Duration // 大于 0 时加 Redis 锁，集群中同一时刻只有一个实例回源
+	LockWait    time.Duration // 未抢到锁时等待其他实例回源的最长时间，超时后自行回源
+	Timeout     time.Duration // 单次回源超时，0 不限；回源不随调用方 ctx 取消
+}
+
+// LocalOptions 进程内一级缓存选项
+type LocalOptions struct {
+	Size    int           // 最大条目数，默认 10000
+	TTL     time.Duration // 条目存活时间，默认 1 分钟，兜底丢失的失效消息
+	Channel string        // 失效广播频道，默认当前实例的前缀 + ":__local__"
+}
+
+// CacheStats 一级与二级缓存命中统计
+// Redis 命中按 get、hget、getex、getdel、mget、hmget、hgetall 命令统计，包括管道中的命令
+type CacheStats struct {
+	LocalHits   uint64
+	LocalMisses uint64
+	RedisHits   uint64
+	RedisMisses uint64
+}
+
+// Message 订阅消息，频道与模式均已去掉缓存前缀
+type Message struct {
+	Channel string
+	Pattern string // 模式订阅时匹配的模式
+	Payload string
+}
+
+// Subscription 订阅句柄
+// 连接断开后 go-redis 会自动重连并重新订阅，断线期间的消息不会补发
+type Subscription interface {
+	// 取消订阅后立即返回，不等待回调，可在回调中调用
+	Close() error
+	// 取消订阅且正在执行的回调全部结束后关闭，在回调外等待
+	Done() <-chan struct{}
+}
+
+// BatchResult 批量命令的单条结果，顺序与入队顺序一致
+type BatchResult struct {
+	Name string // 命令名
+	Key  string // 不带前缀的键
+	Val  any    // 命令返回值
+	Err  error  // 键不存在时为 ErrNotFound
+}
+
+// Batch 批量命令构建器，键自动加前缀
+// 集群模式下 Pipeline 按节点拆分发送；TxPipeline 的所有键必须位于同一槽，
+// 跨槽时整批返回 CROSSSLOT 错误，应使用 hash tag 让相关键落在同一槽
+type Batch interface {
+	// 已入队命令数
+	Len() int
+	// 执行并返回每条命令的结果，error 为第一条失败命令的错误（不含键不存在）
+	Exec(ctx context.Context) ([]BatchResult, error)
+
+	Set(key string, value any, expiration time.Duration) Batch
+	SetNX(key string, value any, expiration time.Duration) Batch
+	SetXX(key string, value any, expiration time.Duration) Batch
+	Incr(key string) Batch
+	IncrBy(key string, val int64) Batch
+	Decr(key string) Batch
+	DecrBy(key string, val int64) Batch
+	Exists(key string) Batch
+	Get(key string) Batch
+	GetSet(key string, value any) Batch
+	Delete(key string) Batch
+	Unlink(key string) Batch
+	HExists(key, field string) Batch
+	HGet(key, field string) Batch
+	HGetAll(key string) Batch
+	HKeys(key string) Batch
+	HLen(key string) Batch
+	HSet(key string, values ...any) Batch
+	HIncrBy(key, field string, incr int64) Batch
+	HDel(key string, fields ...string) Batch
+	Expire(key string, expiration time.Duration) Batch
+	PExpire(key string, expiration time.Duration) Batch
+	ExpireAt(key string, tm time.Time) Batch
+	PExpireAt(key string, tm time.Time) Batch
+	GetBit(key string, offset int64) Batch
+	SetBit(key string, offset int64, val int) Batch
+	BitCount(key string, start, end int64) Batch
+	LLen(key string) Batch
+	LPush(key string, values ...any) Batch
+	LPop(key string) Batch
+	RPush(key string, values ...any) Batch
+	RPop(key string) Batch
+	LRange(key string, start, stop int64) Batch
+}
+
+// Tx WATCH 乐观事务，在 fn 中读取被监视的键，再通过 Exec 提交写命令
+type Tx interface {
+	// 在事务连接上读取数据，键不存在时返回 false
+	Get(ctx context.Context, key string) (string, bool, error)
+	// 在事务连接上读取字段，字段不存在时返回 false
+	HGet(ctx context.Context, key, field string) (string, bool, error)
+	// 在事务连接上读取整个哈希
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	// 以 MULTI/EXEC 提交 fn 中入队的命令
+	Exec(ctx context.Context, fn func(b Batch)) ([]BatchResult, error)
+}
+
+// BloomOptions 布隆过滤器选项
+type BloomOptions struct {
+	Capacity  int64         // 预计元素数，默认 100 万
+	ErrorRate float64       // 误判率，默认 0.01
+	TTL       time.Duration // 首次写入时设置的过期时间，0 不过期，用于按天等周期重置
+}
+
+// Bloom 基于位图的布隆过滤器，整个过滤器位于单个键，集群下安全
+// 同名过滤器的容量与误判率须保持一致，否则位偏移不同会导致误判
+type Bloom interface {
+	// 添加元素，返回每个元素此前是否不存在（可能误判为已存在）
+	Add(ctx context.Context, items ...string) ([]bool, error)
+	// 判断元素是否可能存在，返回 false 时一定不存在
+	Exists(ctx context.Context, items ...string) ([]bool, error)
+	// 清空过滤器
+	Reset(ctx context.Context) error
+}
+
+// QueueOptions 任务队列选项
+type QueueOptions struct {
+	Group       string        // 消费组，默认 workers
+	Visibility  time.Duration // 可见性超时，任务出队后超过该时间未确认将被重新投递，默认 30 秒
+	MaxAttempts int           // 最大尝试次数，超过后进入死信队列，默认 5
+	Block       time.Duration // 出队阻塞等待时间，默认 5 秒
+	Promote     time.Duration // Run 期间将到期延迟任务移入流的间隔，默认 1 秒
+}
+
+// Job 出队的任务
+type Job struct {
+	ID       string // 流消息 ID
+	Payload  []byte
+	Attempts int // 含本次在内的尝试次数
+}
+
+// Queue 基于 Stream 消费组的可靠任务队列
+// 流、延迟集合、死信流使用同一 hash tag，集群下位于同一槽
+type Queue interface {
+	// 入队，delay 大于 0 时为延迟任务
+	Enqueue(ctx context.Context, payload []byte, delay time.Duration) error
+	// 出队，优先取回可见性超时的任务，无任务时阻塞至 Block 后返回 ErrNotFound
+	// 超过最大尝试次数的任务直接转入死信队列；到期的延迟任务在出队前移入流，阻塞期间到期的不会唤醒本次出队
+	Dequeue(ctx context.Context, consumer string) (*Job, error)
+	// 处理成功，确认并删除任务
+	Ack(ctx context.Context, job *Job) error
+	// 处理失败，delay 后重试，达到最大尝试次数时转入死信队列
+	Nack(ctx context.Context, job *Job, delay time.Duration) error
+	// 重置任务的可见性超时，长任务处理期间定期调用
+	Extend(ctx context.Context, job *Job, consumer string) error
+	// 待处理任务数（不含延迟任务）
+	Len(ctx context.Context) (int64, error)
+	// 读取死信任务
+	DeadLetters(ctx context.Context, count int64) ([]*Job, error)
+	// 启动 concurrency 个 worker 处理任务，ctx 取消后不再出队，等待处理中的任务完成后返回
+	// handler 返回错误或 panic 时按 Nack 处理，重试间隔随尝试次数线性增长
+	// 运行期间按 Promote 间隔将到期的延迟任务移入流，阻塞中的 worker 随即被唤醒
+	Run(ctx context.Context, consumer string, concurrency int, handler func(ctx context.Context, job *Job) error)
+}
+
+// RateResult 限流结果
+type RateResult struct {
+	Allowed    bool
+	Remaining  int64         // 当前窗口剩余次数
+	RetryAfter time.Duration // 被拒绝时距下次允许的时间
+	ResetAfter time.Duration // 距计数完全恢复的时间
+}
+
+// NamespaceOptions 命名空间选项
+type NamespaceOptions struct {
+	HashTag bool          // 命名空间名加 hash tag，空间内的键位于同一集群槽，可用于多键命令与事务
+	Refresh time.Duration // 代数本地缓存时长，其他实例失效命名空间后最长经过该时间可见，默认 1 秒
+}
+
+// PrefixOptions 按前缀批量操作选项
+type PrefixOptions struct {
+	Batch    int              // 每批处理的键数，默认 500
+	Interval time.Duration    // 批次之间的间隔，用于限速
+	Progress func(done int64) // 每批完成后回调累计处理数
+}
@@ -0,0 +1,38 @@
+package plugin
+
+import (
+	"github.com/livexy/plugin/dber"
+	"github.com/livexy/plugins/dbbase"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// SQLite 方言，使用纯 Go 驱动，无需 cgo
+// 内存库多个连接互不共享，请使用 file::memory:?cache=shared 形式的 DSN
+var dialect = dbbase.Dialect{
+	Name:  "sqlite",
+	Open:  sqlite.Open,
+	Slots: 32766,
+
+	IfNull: "ifnull",
+	If:     "iif",
+	GroupConcat: func(field string) string {
+		return "group_concat(" + field + ")"
+	},
+	Helper: dbbase.SQLiteHelper{},
+	// 与 PostgreSQL 相同的 excluded 语法
+	ExAdd: func(field string, val any) any {
+		return gorm.Expr(`"excluded"."`+field+`"+?`, val)
+	},
+
+	IDMode:       dbbase.IDReturning,
+	LastInsertID: "select last_insert_rowid() as id",
+
+	Upsert: dbbase.UpsertOnConflict,
+}
+
+// New 创建一个新的 SQLite 数据库适配实例
+func New() dber.Dber {
+	return dbbase.New(dialect)
+}
@@ -0,0 +1,34 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/livexy/plugins/dbbasetest"
+)
+
+func TestDryRun(t *testing.T) {
+	dbbasetest.Run(t, dbbasetest.Open(t, dialect, dialect.Open(":memory:")), dbbasetest.Suite{
+		Upsert: dbbasetest.Snapshots{
+			"model":         "INSERT INTO `stock` (`name`, `qty`, `id`) VALUES (\"a\",2,1) ON CONFLICT (`id`) DO UPDATE SET `name` = `excluded`.`name`, `qty` = `excluded`.`qty`",
+			"autoIncrement": "INSERT INTO `stock` (`name`, `qty`) VALUES (\"a\",2) ON CONFLICT (`id`) DO UPDATE SET `name` = `excluded`.`name`, `qty` = `excluded`.`qty`",
+			"updates":       "INSERT INTO `stock` (`id`, `name`, `qty`) VALUES (1,\"a\",2), (2,\"b\",3) ON CONFLICT (`id`) DO UPDATE SET `name` = `excluded`.`name`",
+			"increments":    "INSERT INTO `stock` (`id`, `name`, `qty`) VALUES (1,\"a\",2), (2,\"b\",3) ON CONFLICT (`id`) DO UPDATE SET `qty` = `stock`.`qty` + 1",
+			"doNothing":     "INSERT INTO `stock` (`id`, `name`, `qty`) VALUES (1,\"a\",2), (2,\"b\",3) ON CONFLICT (`id`) DO NOTHING",
+		},
+		Helper: dbbasetest.Snapshots{
+			"date":      `SELECT strftime('%Y-%m-%d 00:00:00', created_at), strftime('%Y-%m-%d %H:%M:%S', created_at), strftime('%Y-%m-%d %H:%M:%S', created_at), datetime(created_at, '-1 months'), datetime(created_at, '+2 hours'), cast((julianday(updated_at) - julianday(created_at)) * 1 as integer), ((cast(strftime('%Y', updated_at) as integer) - cast(strftime('%Y', created_at) as integer)) * 12 + cast(strftime('%m', updated_at) as integer) - cast(strftime('%m', created_at) as integer)) FROM event`,
+			"string":    `SELECT (ifnull(first_name, '') || ifnull(' ', '') || ifnull(last_name, '')), substr(name, 2, 3), substr(name, 2) FROM person WHERE name LIKE "%it's%"`,
+			"json":      `SELECT json_extract(data, '$.user.name') FROM event WHERE active = 1`,
+			"aggregate": `SELECT dept, group_concat(name, '; ') FROM person GROUP BY dept`,
+			"paging": `SELECT id FROM event ORDER BY random() LIMIT 10 OFFSET 20;
+SELECT id FROM event ORDER BY id LIMIT -1 OFFSET 5`,
+		},
+		CRUD: dbbasetest.Snapshots{
+			"create": "INSERT INTO `stock` (`name`,`qty`) VALUES (\"a\",1) RETURNING `id`",
+			"find":   "SELECT * FROM `stock` WHERE qty > 1 ORDER BY id LIMIT 10 OFFSET 20",
+			"update": "UPDATE `stock` SET `name`=\"b\",`qty`=qty + 1 WHERE `id` = 1",
+			"delete": "DELETE FROM `stock` WHERE `stock`.`id` = 1",
+			"cond":   "SELECT id, ifnull(name, '') AS name, CASE WHEN qty > 10 THEN 'many' ELSE 'few' END AS level FROM `stock`",
+		},
+	})
+}
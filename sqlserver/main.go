@@ -0,0 +1,14 @@
+package main
+
+import (
+	"github.com/livexy/plugin/dber"
+	plug "github.com/livexy/plugins/sqlserver/plugin"
+)
+
+var Plugin plugin
+
+type plugin struct{}
+
+func (p plugin) New() dber.Dber {
+	return plug.New()
+}
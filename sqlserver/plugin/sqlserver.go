@@ -0,0 +1,39 @@
+package plugin
+
+import (
+	"github.com/livexy/plugin/dber"
+	"github.com/livexy/plugins/dbbase"
+
+	"gorm.io/driver/sqlserver"
+	"gorm.io/gorm"
+)
+
+// SQL Server 方言，要求 2017 及以上版本（string_agg）
+// 插入时驱动通过 OUTPUT INSERTED 回填模型，map 通过 @@IDENTITY 取回，SCOPE_IDENTITY() 在单独批次中为空
+var dialect = dbbase.Dialect{
+	Name:  "sqlserver",
+	Open:  sqlserver.Open,
+	Slots: 2098, // 上限 2100 个参数，驱动经 sp_executesql 执行时语句与参数声明占用两个
+
+	IfNull: "isnull",
+	If:     "iif",
+	GroupConcat: func(field string) string {
+		return "string_agg(" + field + ", ',')"
+	},
+	Helper: dbbase.SQLServerHelper{},
+	// MERGE 语句的源数据别名为 excluded
+	ExAdd: func(field string, val any) any {
+		return gorm.Expr(`"excluded"."`+field+`"+?`, val)
+	},
+
+	IDMode:       dbbase.IDLastInsert,
+	LastInsertID: "select cast(@@IDENTITY as bigint) as id",
+
+	Upsert:      dbbase.UpsertMerge,
+	MergeSuffix: ";",
+}
+
+// New 创建一个新的 SQL Server 数据库适配实例
+func New() dber.Dber {
+	return dbbase.New(dialect)
+}